@@ -1,5 +1,7 @@
 package finn
 
+import "github.com/marcosQuesada/finn/iban"
+
 // Account wraps account data
 type Account struct {
 	AccoundData *AccoundData `json:"data"`
@@ -34,6 +36,36 @@ type Attributes struct {
 	PrivateID             *PrivateIdentification      `json:"private_identification,omitempty"`
 	OrganisationID        *OrganisationIdentification `json:"organisation_identification,omitempty"`
 	Status                string                      `json:"status,omitempty"`
+	CustomerID            string                      `json:"customer_id,omitempty"`
+}
+
+// GenerateIban derives Iban from country, bank id and account number where the country scheme allows it,
+// GB and IE bank codes are taken from Bic and prefixed to bank id, NL ones, which have no bank id, from Bic alone
+func (a *Attributes) GenerateIban() error {
+	bankID := a.BankID
+	switch a.Country {
+	case "GB", "IE":
+		b, err := iban.ParseBIC(a.Bic)
+		if err != nil {
+			return err
+		}
+		bankID = b.BankCode + bankID
+	case "NL":
+		b, err := iban.ParseBIC(a.Bic)
+		if err != nil {
+			return err
+		}
+		bankID = b.BankCode
+	}
+
+	i, err := iban.Generate(a.Country, bankID, a.AccountNumber)
+	if err != nil {
+		return err
+	}
+
+	a.Iban = i.Electronic()
+
+	return nil
 }
 
 // PrivateIdentification defines account owner details
@@ -95,4 +127,6 @@ type LinkList struct {
 	First string `json:"first"`
 	Last  string `json:"last"`
 	Self  string `json:"self"`
+	Next  string `json:"next,omitempty"`
+	Prev  string `json:"prev,omitempty"`
 }
@@ -349,6 +349,47 @@ func TestUnMarshalRawValidEventsRelationshipsFromAccountData(t *testing.T) {
 	}
 }
 
+func TestGenerateIbanDerivesIbanFromAttributes(t *testing.T) {
+	tests := []struct {
+		attr *Attributes
+		iban string
+	}{
+		{
+			attr: &Attributes{Country: "GB", Bic: "NWBKGB22", BankID: "400300", AccountNumber: "41426819"},
+			iban: "GB16NWBK40030041426819",
+		},
+		{
+			attr: &Attributes{Country: "ES", BankID: "21000418", AccountNumber: "0200051332"},
+			iban: "ES9121000418450200051332",
+		},
+		{
+			attr: &Attributes{Country: "NL", Bic: "ABNANL2A", AccountNumber: "0417164300"},
+			iban: "NL91ABNA0417164300",
+		},
+	}
+
+	for _, test := range tests {
+		if err := test.attr.GenerateIban(); err != nil {
+			t.Fatalf("unexpected error generating iban, error %v", err)
+		}
+
+		if got := test.attr.Iban; got != test.iban {
+			t.Errorf("iban does not match, expected %s got %s", test.iban, got)
+		}
+	}
+}
+
+func TestGenerateIbanReturnsErrorOnUnsupportedScheme(t *testing.T) {
+	attr := &Attributes{Country: "US", BankID: "021000021", AccountNumber: "123456"}
+	if err := attr.GenerateIban(); err == nil {
+		t.Error("expected unsupported scheme error")
+	}
+
+	if attr.Iban != "" {
+		t.Errorf("unexpected iban, got %s", attr.Iban)
+	}
+}
+
 func TestUnMarshalRawListResponseToAccountsList(t *testing.T) {
 	accs := &AccountList{}
 	err := json.Unmarshal([]byte(listResponse), accs)
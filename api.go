@@ -2,19 +2,24 @@ package finn
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
 
 	client "github.com/marcosQuesada/finn/http"
+	"github.com/marcosQuesada/finn/trace"
 )
 
 const apVersion = "v1"
 const path = "organisation/accounts"
 
-// ErrVersionConflict happens on version conflict error
+// ErrVersionConflict happens on version conflict error, it is returned wrapped, match it with errors.Is
 var ErrVersionConflict = errors.New("version conflict")
 
+// ErrInvalidAccount happens on nil account or account data, it is returned wrapped, match it with errors.Is
+var ErrInvalidAccount = errors.New("invalid account")
+
 // httpClient defines http transport
 type httpClient interface {
 	Do(ctx context.Context, req *http.Request, v interface{}) (*http.Response, error)
@@ -23,28 +28,75 @@ type httpClient interface {
 
 // APIClient defines an account http api client
 type APIClient struct {
-	api httpClient
+	api      httpClient
+	validate bool
+	metrics  *instruments
+	tracer   trace.Tracer
+}
+
+// Option configures APIClient
+type Option func(*APIClient)
+
+// WithValidation validates accounts before sending them on Create
+func WithValidation() Option {
+	return func(c *APIClient) {
+		c.validate = true
+	}
 }
 
 // NewAPIClient instantiates api client
-func NewAPIClient(api httpClient) *APIClient {
-	return &APIClient{
-		api: api,
+func NewAPIClient(api httpClient, opts ...Option) *APIClient {
+	c := &APIClient{
+		api:    api,
+		tracer: trace.Noop{},
 	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c
 }
 
-// Create invokes account creation
-func (c *APIClient) Create(ctx context.Context, account *Account) (*Account, error) {
+// Create invokes account creation, account is validated first when validation is enabled,
+// requests carry an idempotency key and, for accounts with id, conflicts coming from replayed requests
+// are resolved returning stored account, ErrDuplicateAccount is returned when stored account differs
+func (c *APIClient) Create(ctx context.Context, account *Account, opts ...CreateOption) (*Account, error) {
+	var acc *Account
+	err := c.observe(ctx, opCreate, func(ctx context.Context) (err error) {
+		acc, err = c.create(ctx, account, opts)
+		return err
+	})
+
+	return acc, err
+}
+
+// create invokes account creation
+func (c *APIClient) create(ctx context.Context, account *Account, opts []CreateOption) (*Account, error) {
+	if c.validate {
+		if err := account.Validate(); err != nil {
+			return nil, err
+		}
+	}
+
+	o := newCreateOptions(opts)
 	uri := fmt.Sprintf("%s/%s", apVersion, path)
 	req, respErr := c.api.CreateRequest(http.MethodPost, uri, account)
 	if respErr != nil {
-		return nil, fmt.Errorf("unexpected error creating request, error %v", respErr)
+		return nil, fmt.Errorf("unexpected error creating request, error %w", respErr)
+	}
+	if o.idempotencyKey != "" {
+		req.Header.Set(client.IdempotencyKeyHeader, o.idempotencyKey)
 	}
 
 	acc := &Account{}
 	resp, respErr := c.api.Do(ctx, req, acc)
+	if resp != nil && resp.StatusCode == http.StatusConflict && o.idempotencyKey != "" && hasID(account) {
+		return c.replayed(ctx, account)
+	}
+
 	if respErr != nil {
-		return nil, fmt.Errorf("unexpected error executing http request, error %v", respErr)
+		return nil, fmt.Errorf("unexpected error executing http request, error %w", respErr)
 	}
 
 	if resp.StatusCode != http.StatusCreated {
@@ -56,6 +108,17 @@ func (c *APIClient) Create(ctx context.Context, account *Account) (*Account, err
 
 // Fetch gets user account by uuid
 func (c *APIClient) Fetch(ctx context.Context, uuid string) (*Account, error) {
+	var acc *Account
+	err := c.observe(ctx, opFetch, func(ctx context.Context) (err error) {
+		acc, err = c.fetch(ctx, uuid)
+		return err
+	})
+
+	return acc, err
+}
+
+// fetch gets user account by uuid
+func (c *APIClient) fetch(ctx context.Context, uuid string) (*Account, error) {
 	uri := fmt.Sprintf("%s/%s/%s", apVersion, path, uuid)
 	req, err := c.api.CreateRequest(http.MethodGet, uri, nil)
 	if err != nil {
@@ -71,10 +134,31 @@ func (c *APIClient) Fetch(ctx context.Context, uuid string) (*Account, error) {
 	return a, nil
 }
 
-// List accounts with pagination
-func (c *APIClient) List(ctx context.Context, pags *Pagination) (*AccountList, error) {
-	uri := fmt.Sprintf("%s/%s?%s", apVersion, path, pags.QueryString())
+// List accounts with pagination, ListOptions adds server side filters
+func (c *APIClient) List(ctx context.Context, q Query) (*AccountList, error) {
+	uri := listURI(q)
+
+	return c.list(ctx, uri)
+}
+
+// ListAll returns an iterator over all accounts, starting from query page and following next links
+func (c *APIClient) ListAll(ctx context.Context, q Query) *Iterator {
+	return newIterator(ctx, c, listURI(q))
+}
+
+// list gets an account list page from uri
+func (c *APIClient) list(ctx context.Context, uri string) (*AccountList, error) {
+	var page *AccountList
+	err := c.observe(ctx, opList, func(ctx context.Context) (err error) {
+		page, err = c.listPage(ctx, uri)
+		return err
+	})
+
+	return page, err
+}
 
+// listPage requests an account list page
+func (c *APIClient) listPage(ctx context.Context, uri string) (*AccountList, error) {
 	req, err := c.api.CreateRequest(http.MethodGet, uri, nil)
 	if err != nil {
 		return nil, err
@@ -89,8 +173,115 @@ func (c *APIClient) List(ctx context.Context, pags *Pagination) (*AccountList, e
 	return a, nil
 }
 
+// listURI builds account list uri from query
+func listURI(q Query) string {
+	uri := fmt.Sprintf("%s/%s", apVersion, path)
+	if qs := q.QueryString(); qs != "" {
+		uri = fmt.Sprintf("%s?%s", uri, qs)
+	}
+
+	return uri
+}
+
+// Update patches account attributes, a JSON:API partial document holding account type, id, version
+// and non empty attributes is sent, version enables server optimistic locking, FetchAndUpdate sends
+// changed attributes only, clearing removed ones
+func (c *APIClient) Update(ctx context.Context, account *Account) (*Account, error) {
+	return c.patch(ctx, nil, account)
+}
+
+// patch runs an update operation sending attributes differing from base ones
+func (c *APIClient) patch(ctx context.Context, base map[string]json.RawMessage, account *Account) (*Account, error) {
+	var acc *Account
+	err := c.observe(ctx, opUpdate, func(ctx context.Context) (err error) {
+		acc, err = c.update(ctx, base, account)
+		return err
+	})
+
+	return acc, err
+}
+
+// update patches account attributes differing from base ones
+func (c *APIClient) update(ctx context.Context, base map[string]json.RawMessage, account *Account) (*Account, error) {
+	if account == nil || account.AccoundData == nil {
+		return nil, ErrInvalidAccount
+	}
+
+	doc, err := newPatchDocument(base, account.AccoundData)
+	if err != nil {
+		return nil, err
+	}
+
+	uri := fmt.Sprintf("%s/%s/%s", apVersion, path, account.AccoundData.ID)
+	req, err := c.api.CreateRequest(http.MethodPatch, uri, doc)
+	if err != nil {
+		return nil, err
+	}
+
+	acc := &Account{}
+	resp, respErr := c.api.Do(ctx, req, acc)
+	if resp != nil && resp.StatusCode == http.StatusConflict {
+		return nil, ErrVersionConflict
+	}
+
+	if respErr != nil {
+		return nil, respErr
+	}
+
+	return acc, nil
+}
+
+// FetchAndUpdate fetches account by uuid, applies fn and updates it sending changed attributes only,
+// cleared ones are sent as null, on version conflict the whole cycle is retried until maxAttempts is reached
+func (c *APIClient) FetchAndUpdate(ctx context.Context, uuid string, maxAttempts int, fn func(*Account) error) (*Account, error) {
+	if maxAttempts < 1 {
+		maxAttempts = 1
+	}
+
+	var err error
+	for i := 0; i < maxAttempts; i++ {
+		var acc *Account
+		acc, err = c.Fetch(ctx, uuid)
+		if err != nil {
+			return nil, err
+		}
+
+		if acc.AccoundData == nil {
+			return nil, ErrInvalidAccount
+		}
+
+		var base map[string]json.RawMessage
+		base, err = attributeFields(acc.AccoundData.Attributes)
+		if err != nil {
+			return nil, err
+		}
+
+		if err = fn(acc); err != nil {
+			return nil, err
+		}
+
+		acc, err = c.patch(ctx, base, acc)
+		if err == nil {
+			return acc, nil
+		}
+
+		if !errors.Is(err, ErrVersionConflict) {
+			return nil, err
+		}
+	}
+
+	return nil, fmt.Errorf("update attempts exhausted, error %w", err)
+}
+
 // Delete removes account by user uuid and version
 func (c *APIClient) Delete(ctx context.Context, uuid string, version int) error {
+	return c.observe(ctx, opDelete, func(ctx context.Context) error {
+		return c.delete(ctx, uuid, version)
+	})
+}
+
+// delete removes account by user uuid and version
+func (c *APIClient) delete(ctx context.Context, uuid string, version int) error {
 	uri := fmt.Sprintf("%s/%s/%s?version=%d", apVersion, path, uuid, version)
 
 	req, err := c.api.CreateRequest(http.MethodDelete, uri, nil)
@@ -68,6 +68,19 @@ func TestCreateAccountReturnsInternalServerErrorOnNonStatusAccepted(t *testing.T
 	}
 }
 
+func TestCreateAccountKeepsTransportErrorChain(t *testing.T) {
+	h := &fakeHTTPClient{
+		statusCode: http.StatusBadRequest,
+		err:        client.ErrBadRequest,
+	}
+	api := NewAPIClient(h)
+
+	_, err := api.Create(context.Background(), &Account{AccoundData: &AccoundData{ID: uuid.New().String()}})
+	if !errors.Is(err, client.ErrBadRequest) {
+		t.Errorf("Unexpected error type, got %v", err)
+	}
+}
+
 func TestFetchAccountReturnsAFullPopulatedAccountOnValidStatusCode(t *testing.T) {
 	userID := uuid.New().String()
 	acc := &Account{
@@ -220,13 +233,242 @@ func TestDeleteAccountReturnsConflictErrorOnWrongVersion(t *testing.T) {
 	}
 }
 
+func TestUpdateAccountReturnsUpdatedAccountOnStatusOk(t *testing.T) {
+	userID := uuid.New().String()
+	acc := &Account{
+		AccoundData: &AccoundData{
+			Type:    "accounts",
+			ID:      userID,
+			Version: 0,
+			Attributes: &Attributes{
+				Name:   []string{"Samantha Holder"},
+				Status: "confirmed",
+			},
+		}}
+	updated := &Account{
+		AccoundData: &AccoundData{
+			Type:       "accounts",
+			ID:         userID,
+			Version:    1,
+			Attributes: acc.AccoundData.Attributes,
+		}}
+	rawAccount, err := json.Marshal(updated)
+	if err != nil {
+		t.Fatalf("unexpected error marshalling account, error %v", err)
+	}
+
+	h := &fakeHTTPClient{
+		statusCode: http.StatusOK,
+		body:       rawAccount,
+	}
+	api := NewAPIClient(h)
+
+	a, err := api.Update(context.Background(), acc)
+	if err != nil {
+		t.Fatalf("unexpected error updating account, error %v", err)
+	}
+
+	if !reflect.DeepEqual(updated, a) {
+		t.Error("accounts are not equal")
+	}
+}
+
+func TestUpdateAccountReturnsConflictErrorOnStatusConflict(t *testing.T) {
+	h := &fakeHTTPClient{
+		statusCode: http.StatusConflict,
+		err:        client.ErrInternalServer,
+	}
+	api := NewAPIClient(h)
+
+	acc := &Account{AccoundData: &AccoundData{ID: uuid.New().String(), Version: 3}}
+	_, err := api.Update(context.Background(), acc)
+	if !errors.Is(err, ErrVersionConflict) {
+		t.Errorf("unexpected error type, expected conflict got %v", err)
+	}
+}
+
+func TestUpdateAccountReturnsInvalidAccountOnNilAccountData(t *testing.T) {
+	api := NewAPIClient(&fakeHTTPClient{})
+
+	_, err := api.Update(context.Background(), &Account{})
+	if !errors.Is(err, ErrInvalidAccount) {
+		t.Errorf("unexpected error type, expected invalid account got %v", err)
+	}
+}
+
+func TestUpdateAccountSendsAPartialDocument(t *testing.T) {
+	h := &fakeHTTPClient{statusCode: http.StatusOK}
+	api := NewAPIClient(h)
+
+	acc := &Account{AccoundData: &AccoundData{
+		ID:             "fakeUserID",
+		OrganisationID: uuid.New().String(),
+		Version:        2,
+		Attributes:     &Attributes{Status: "closed"},
+	}}
+	if _, err := api.Update(context.Background(), acc); err != nil {
+		t.Fatalf("unexpected error updating account, error %v", err)
+	}
+
+	raw, err := json.Marshal(h.sent)
+	if err != nil {
+		t.Fatalf("unexpected error marshalling document, error %v", err)
+	}
+
+	want := `{"data":{"type":"accounts","id":"fakeUserID","version":2,"attributes":{"status":"closed"}}}`
+	if got := string(raw); got != want {
+		t.Errorf("unexpected patch document, expected %s got %s", want, got)
+	}
+}
+
+func TestUpdateAccountWithoutAttributesClearsNothing(t *testing.T) {
+	h := &fakeHTTPClient{statusCode: http.StatusOK}
+	api := NewAPIClient(h)
+
+	acc := &Account{AccoundData: &AccoundData{ID: "fakeUserID", Version: 2}}
+	if _, err := api.Update(context.Background(), acc); err != nil {
+		t.Fatalf("unexpected error updating account, error %v", err)
+	}
+
+	raw, err := json.Marshal(h.sent)
+	if err != nil {
+		t.Fatalf("unexpected error marshalling document, error %v", err)
+	}
+
+	want := `{"data":{"type":"accounts","id":"fakeUserID","version":2}}`
+	if got := string(raw); got != want {
+		t.Errorf("unexpected patch document, expected %s got %s", want, got)
+	}
+}
+
+func TestFetchAndUpdateSendsChangedAttributesOnly(t *testing.T) {
+	rawAccount, err := json.Marshal(&Account{AccoundData: &AccoundData{
+		Type:       "accounts",
+		ID:         "fakeUserID",
+		Version:    1,
+		Attributes: &Attributes{Country: "GB", Status: "confirmed", Bic: "NWBKGB22"},
+	}})
+	if err != nil {
+		t.Fatalf("unexpected error marshalling account, error %v", err)
+	}
+
+	update := &fakeHTTPClient{statusCode: http.StatusOK, body: rawAccount}
+	h := &sequenceHTTPClient{responses: []*fakeHTTPClient{{statusCode: http.StatusOK, body: rawAccount}, update}}
+	api := NewAPIClient(h)
+
+	_, err = api.FetchAndUpdate(context.Background(), "fakeUserID", 1, func(acc *Account) error {
+		acc.AccoundData.Attributes.Status = ""
+		acc.AccoundData.Attributes.Name = []string{"Samantha Holder"}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error updating account, error %v", err)
+	}
+
+	raw, err := json.Marshal(h.sent)
+	if err != nil {
+		t.Fatalf("unexpected error marshalling document, error %v", err)
+	}
+
+	want := `{"data":{"type":"accounts","id":"fakeUserID","version":1,"attributes":{"name":["Samantha Holder"],"status":null}}}`
+	if got := string(raw); got != want {
+		t.Errorf("unexpected patch document, expected %s got %s", want, got)
+	}
+}
+
+func TestCreateAccountSendsFullDocument(t *testing.T) {
+	h := &fakeHTTPClient{statusCode: http.StatusCreated}
+	api := NewAPIClient(h)
+
+	acc := &Account{AccoundData: &AccoundData{Type: "accounts", ID: "fakeUserID"}}
+	if _, err := api.Create(context.Background(), acc); err != nil {
+		t.Fatalf("unexpected error creating account, error %v", err)
+	}
+
+	raw, err := json.Marshal(h.sent)
+	if err != nil {
+		t.Fatalf("unexpected error marshalling document, error %v", err)
+	}
+
+	want := `{"data":{"type":"accounts","id":"fakeUserID","organisation_id":"","version":0,"attributes":null,"relationships":null}}`
+	if got := string(raw); got != want {
+		t.Errorf("unexpected create document, expected %s got %s", want, got)
+	}
+}
+
+func TestFetchAndUpdateRetriesOnVersionConflict(t *testing.T) {
+	userID := uuid.New().String()
+	rawAccount, err := json.Marshal(&Account{AccoundData: &AccoundData{ID: userID, Version: 1}})
+	if err != nil {
+		t.Fatalf("unexpected error marshalling account, error %v", err)
+	}
+
+	h := &sequenceHTTPClient{
+		responses: []*fakeHTTPClient{
+			{statusCode: http.StatusOK, body: rawAccount},
+			{statusCode: http.StatusConflict, err: client.ErrInternalServer},
+			{statusCode: http.StatusOK, body: rawAccount},
+			{statusCode: http.StatusOK, body: rawAccount},
+		},
+	}
+	api := NewAPIClient(h)
+
+	calls := 0
+	a, err := api.FetchAndUpdate(context.Background(), userID, 3, func(acc *Account) error {
+		calls++
+		acc.AccoundData.Attributes = &Attributes{Status: "closed"}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error updating account, error %v", err)
+	}
+
+	if a == nil || a.AccoundData == nil || a.AccoundData.ID != userID {
+		t.Fatal("unexpected updated account")
+	}
+
+	if got, want := calls, 2; got != want {
+		t.Errorf("unexpected modify calls, expected %d got %d", want, got)
+	}
+}
+
+func TestFetchAndUpdateReturnsConflictErrorWhenAttemptsAreExhausted(t *testing.T) {
+	rawAccount, err := json.Marshal(&Account{AccoundData: &AccoundData{ID: "fakeUserID"}})
+	if err != nil {
+		t.Fatalf("unexpected error marshalling account, error %v", err)
+	}
+
+	h := &sequenceHTTPClient{
+		responses: []*fakeHTTPClient{
+			{statusCode: http.StatusOK, body: rawAccount},
+			{statusCode: http.StatusConflict, err: client.ErrInternalServer},
+			{statusCode: http.StatusOK, body: rawAccount},
+			{statusCode: http.StatusConflict, err: client.ErrInternalServer},
+		},
+	}
+	api := NewAPIClient(h)
+
+	_, err = api.FetchAndUpdate(context.Background(), "fakeUserID", 2, func(acc *Account) error {
+		return nil
+	})
+	if !errors.Is(err, ErrVersionConflict) {
+		t.Errorf("unexpected error type, expected conflict got %v", err)
+	}
+}
+
 type fakeHTTPClient struct {
 	statusCode int
 	body       []byte
 	err        error
+	uri        string
+	req        *http.Request
+	ctx        context.Context
+	sent       interface{}
 }
 
 func (f *fakeHTTPClient) Do(ctx context.Context, req *http.Request, v interface{}) (*http.Response, error) {
+	f.req = req
+	f.ctx = ctx
 	if v != nil && f.body != nil {
 		err := json.Unmarshal(f.body, &v)
 		if err != nil {
@@ -241,5 +483,25 @@ func (f *fakeHTTPClient) Do(ctx context.Context, req *http.Request, v interface{
 }
 
 func (f *fakeHTTPClient) CreateRequest(method, url string, body interface{}) (*http.Request, error) {
+	f.uri = url
+	f.sent = body
+	return http.NewRequest(method, url, nil)
+}
+
+type sequenceHTTPClient struct {
+	responses []*fakeHTTPClient
+	calls     int
+	sent      interface{}
+}
+
+func (s *sequenceHTTPClient) Do(ctx context.Context, req *http.Request, v interface{}) (*http.Response, error) {
+	f := s.responses[s.calls]
+	s.calls++
+
+	return f.Do(ctx, req, v)
+}
+
+func (s *sequenceHTTPClient) CreateRequest(method, url string, body interface{}) (*http.Request, error) {
+	s.sent = body
 	return http.NewRequest(method, url, nil)
 }
@@ -0,0 +1,215 @@
+package finn
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+const defaultBatchWorkers = 8
+
+// ErrBatchAborted happens on batch items not completed because a fail fast batch already failed
+var ErrBatchAborted = errors.New("batch aborted")
+
+// BatchOption configures FetchMany and DeleteMany calls
+type BatchOption func(*batchOptions)
+
+// batchOptions holds batch call options
+type batchOptions struct {
+	workers  int
+	failFast bool
+}
+
+// WithWorkers bounds concurrent requests, 8 by default
+func WithWorkers(n int) BatchOption {
+	return func(o *batchOptions) {
+		if n > 0 {
+			o.workers = n
+		}
+	}
+}
+
+// WithFailFast stops batch on first failed item, in flight requests get cancelled and pending
+// items fail with ErrBatchAborted, by default every item is executed
+func WithFailFast() BatchOption {
+	return func(o *batchOptions) {
+		o.failFast = true
+	}
+}
+
+// FetchResult holds a FetchMany item outcome
+type FetchResult struct {
+	ID      string
+	Account *Account
+	Err     error
+}
+
+// DeleteResult holds a DeleteMany item outcome
+type DeleteResult struct {
+	ID      string
+	Version int
+	Err     error
+}
+
+// BatchError reports failed batch items, it unwraps to the first failure, which on fail fast
+// batches is the one that stopped the batch
+type BatchError struct {
+	Failed int
+	Total  int
+	First  error
+}
+
+// Error describes failed items
+func (e *BatchError) Error() string {
+	return fmt.Sprintf("%d of %d batch items failed, first error %v", e.Failed, e.Total, e.First)
+}
+
+// Unwrap returns first failure
+func (e *BatchError) Unwrap() error {
+	return e.First
+}
+
+// FetchMany fetches accounts by id concurrently, results keep ids order, a *BatchError is returned
+// when any item fails and context error when ctx is done before batch completes
+func (c *APIClient) FetchMany(ctx context.Context, ids []string, opts ...BatchOption) ([]*FetchResult, error) {
+	results := make([]*FetchResult, len(ids))
+	for i, id := range ids {
+		results[i] = &FetchResult{ID: id}
+	}
+
+	errs, err := runBatch(ctx, ids, newBatchOptions(opts), func(ctx context.Context, i int) error {
+		var fetchErr error
+		results[i].Account, fetchErr = c.Fetch(ctx, ids[i])
+		return fetchErr
+	})
+
+	for i := range results {
+		results[i].Err = errs[i]
+	}
+
+	return results, err
+}
+
+// DeleteMany deletes accounts by id and version concurrently, as versions map has no order results are
+// returned sorted by id, not in insertion order, each result holds its id and version to line it up with
+// the input, a *BatchError is returned when any item fails and context error when ctx is done before
+// batch completes
+func (c *APIClient) DeleteMany(ctx context.Context, versions map[string]int, opts ...BatchOption) ([]*DeleteResult, error) {
+	ids := make([]string, 0, len(versions))
+	for id := range versions {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	errs, err := runBatch(ctx, ids, newBatchOptions(opts), func(ctx context.Context, i int) error {
+		return c.Delete(ctx, ids[i], versions[ids[i]])
+	})
+
+	results := make([]*DeleteResult, len(ids))
+	for i, id := range ids {
+		results[i] = &DeleteResult{ID: id, Version: versions[id], Err: errs[i]}
+	}
+
+	return results, err
+}
+
+// newBatchOptions applies options over defaults
+func newBatchOptions(opts []BatchOption) *batchOptions {
+	o := &batchOptions{
+		workers: defaultBatchWorkers,
+	}
+
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	return o
+}
+
+// runBatch executes an item per id on a bounded worker pool returning item errors in ids order,
+// items never executed fail with ErrBatchAborted or with ctx error when ctx is done
+func runBatch(ctx context.Context, ids []string, o *batchOptions, do func(ctx context.Context, i int) error) ([]error, error) {
+	bctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	errs := make([]error, len(ids))
+	done := make([]bool, len(ids))
+	var mu sync.Mutex
+	var trigger error
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < o.workers && w < len(ids); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				err := do(bctx, i)
+
+				mu.Lock()
+				done[i] = true
+				if trigger != nil && errors.Is(err, context.Canceled) && ctx.Err() == nil {
+					err = ErrBatchAborted
+				}
+				errs[i] = err
+
+				if err != nil && o.failFast && trigger == nil {
+					trigger = fmt.Errorf("id %s, error %w", ids[i], err)
+					cancel()
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+dispatch:
+	for i := range ids {
+		select {
+		case jobs <- i:
+		case <-bctx.Done():
+			break dispatch
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	reason := ErrBatchAborted
+	if err := ctx.Err(); err != nil {
+		reason = err
+	}
+
+	for i := range done {
+		if !done[i] {
+			errs[i] = reason
+		}
+	}
+
+	if err := ctx.Err(); err != nil {
+		return errs, err
+	}
+
+	return errs, batchError(ids, errs, trigger)
+}
+
+// batchError summarises item errors, nil when every item succeeded
+func batchError(ids []string, errs []error, first error) error {
+	failed := 0
+	for i, err := range errs {
+		if err == nil {
+			continue
+		}
+
+		failed++
+		if first == nil {
+			first = fmt.Errorf("id %s, error %w", ids[i], err)
+		}
+	}
+
+	if failed == 0 {
+		return nil
+	}
+
+	return &BatchError{Failed: failed, Total: len(errs), First: first}
+}
@@ -0,0 +1,186 @@
+package finn
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	client "github.com/marcosQuesada/finn/http"
+)
+
+func TestFetchManyReturnsResultsInInputOrder(t *testing.T) {
+	h := newBatchHTTPClient(5 * time.Millisecond)
+	h.missing["c"] = true
+	api := NewAPIClient(h)
+
+	ids := []string{"e", "c", "a", "d", "b"}
+	results, err := api.FetchMany(context.Background(), ids, WithWorkers(2))
+
+	var batchErr *BatchError
+	if !errors.As(err, &batchErr) || batchErr.Failed != 1 || batchErr.Total != 5 {
+		t.Fatalf("unexpected batch error, got %v", err)
+	}
+
+	if !errors.Is(err, client.ErrContentNotFound) {
+		t.Errorf("batch error does not unwrap to item error, got %v", err)
+	}
+
+	for i, res := range results {
+		if res.ID != ids[i] {
+			t.Errorf("result %d id does not match, expected %s got %s", i, ids[i], res.ID)
+		}
+
+		if res.ID == "c" {
+			if !errors.Is(res.Err, client.ErrContentNotFound) {
+				t.Errorf("unexpected error type, expected content not found got %v", res.Err)
+			}
+			continue
+		}
+
+		if res.Err != nil || res.Account.AccoundData.ID != res.ID {
+			t.Errorf("unexpected result on %s, error %v", res.ID, res.Err)
+		}
+	}
+
+	if peak := atomic.LoadInt32(&h.peak); peak > 2 {
+		t.Errorf("workers not bounded, expected at most 2 concurrent requests got %d", peak)
+	}
+}
+
+func TestDeleteManyReturnsResultsSortedByID(t *testing.T) {
+	h := newBatchHTTPClient(0)
+	api := NewAPIClient(h)
+
+	results, err := api.DeleteMany(context.Background(), map[string]int{"c": 2, "a": 0, "b": 1})
+	if err != nil {
+		t.Fatalf("unexpected error deleting accounts, error %v", err)
+	}
+
+	for i, id := range []string{"a", "b", "c"} {
+		if results[i].ID != id || results[i].Version != i || results[i].Err != nil {
+			t.Errorf("unexpected result %d, got %+v", i, results[i])
+		}
+	}
+
+	if got := atomic.LoadInt32(&h.calls); got != 3 {
+		t.Errorf("delete calls do not match, expected 3 got %d", got)
+	}
+}
+
+func TestFetchManyFailFastAbortsPendingItems(t *testing.T) {
+	h := newBatchHTTPClient(20 * time.Millisecond)
+	h.missing["id-0"] = true
+	api := NewAPIClient(h)
+
+	ids := make([]string, 20)
+	for i := range ids {
+		ids[i] = fmt.Sprintf("id-%d", i)
+	}
+
+	results, err := api.FetchMany(context.Background(), ids, WithWorkers(2), WithFailFast())
+	if !errors.Is(err, client.ErrContentNotFound) {
+		t.Fatalf("unexpected error type, expected content not found got %v", err)
+	}
+
+	aborted := 0
+	for _, res := range results {
+		if errors.Is(res.Err, ErrBatchAborted) {
+			aborted++
+		}
+	}
+
+	if aborted < 15 {
+		t.Errorf("pending items not aborted, got %d aborted", aborted)
+	}
+
+	if calls := atomic.LoadInt32(&h.calls); calls > 4 {
+		t.Errorf("batch not stopped on first failure, got %d calls", calls)
+	}
+}
+
+func TestFetchManyStopsOnContextCancellation(t *testing.T) {
+	h := newBatchHTTPClient(time.Second)
+	api := NewAPIClient(h)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	results, err := api.FetchMany(ctx, []string{"a", "b", "c", "d"}, WithWorkers(2))
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("unexpected error type, expected deadline exceeded got %v", err)
+	}
+
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("in flight calls not stopped, took %v", elapsed)
+	}
+
+	for _, res := range results {
+		if !errors.Is(res.Err, context.DeadlineExceeded) {
+			t.Errorf("unexpected error on %s, expected deadline exceeded got %v", res.ID, res.Err)
+		}
+	}
+}
+
+// batchHTTPClient serves accounts named after requested id, tracking concurrent requests
+type batchHTTPClient struct {
+	mu       sync.Mutex
+	missing  map[string]bool
+	delay    time.Duration
+	calls    int32
+	inFlight int32
+	peak     int32
+}
+
+func newBatchHTTPClient(delay time.Duration) *batchHTTPClient {
+	return &batchHTTPClient{
+		missing: make(map[string]bool),
+		delay:   delay,
+	}
+}
+
+func (b *batchHTTPClient) Do(ctx context.Context, req *http.Request, v interface{}) (*http.Response, error) {
+	atomic.AddInt32(&b.calls, 1)
+	n := atomic.AddInt32(&b.inFlight, 1)
+	defer atomic.AddInt32(&b.inFlight, -1)
+
+	b.mu.Lock()
+	if n > b.peak {
+		b.peak = n
+	}
+	b.mu.Unlock()
+
+	t := time.NewTimer(b.delay)
+	defer t.Stop()
+	select {
+	case <-t.C:
+	case <-ctx.Done():
+		return nil, &client.RequestError{Method: req.Method, URL: req.URL.String(), Err: ctx.Err()}
+	}
+
+	id := req.URL.Path[strings.LastIndex(req.URL.Path, "/")+1:]
+	if b.missing[id] {
+		return &http.Response{StatusCode: http.StatusNotFound}, client.ErrContentNotFound
+	}
+
+	if acc, ok := v.(*Account); ok {
+		acc.AccoundData = &AccoundData{ID: id}
+	}
+
+	status := http.StatusOK
+	if req.Method == http.MethodDelete {
+		status = http.StatusNoContent
+	}
+
+	return &http.Response{StatusCode: status}, nil
+}
+
+func (b *batchHTTPClient) CreateRequest(method, url string, body interface{}) (*http.Request, error) {
+	return http.NewRequest(method, url, nil)
+}
@@ -0,0 +1,112 @@
+package bulk
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// checkpoint persists the highest line such that every previous line was processed
+type checkpoint struct {
+	mu      sync.Mutex
+	path    string
+	line    int
+	pending []int
+	done    map[int]bool
+}
+
+// checkpointFile defines checkpoint file content
+type checkpointFile struct {
+	Line int `json:"line"`
+}
+
+// loadCheckpoint reads checkpoint from path, a missing file starts from first line,
+// an empty path disables persistence
+func loadCheckpoint(path string) (*checkpoint, error) {
+	c := &checkpoint{
+		path: path,
+		done: make(map[int]bool),
+	}
+
+	if path == "" {
+		return c, nil
+	}
+
+	b, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return c, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	f := &checkpointFile{}
+	if err := json.Unmarshal(b, f); err != nil {
+		return nil, err
+	}
+	c.line = f.Line
+
+	return c, nil
+}
+
+// dispatched tracks a line sent for processing, lines are dispatched in increasing order
+func (c *checkpoint) dispatched(line int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.pending = append(c.pending, line)
+}
+
+// processed flags line as done, advancing and persisting checkpoint over contiguous processed lines
+func (c *checkpoint) processed(line int) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.done[line] = true
+
+	advanced := false
+	for len(c.pending) > 0 && c.done[c.pending[0]] {
+		c.line = c.pending[0]
+		delete(c.done, c.pending[0])
+		c.pending = c.pending[1:]
+		advanced = true
+	}
+
+	if !advanced {
+		return nil
+	}
+
+	return c.save()
+}
+
+// save writes checkpoint atomically
+func (c *checkpoint) save() error {
+	if c.path == "" {
+		return nil
+	}
+
+	b, err := json.Marshal(&checkpointFile{Line: c.line})
+	if err != nil {
+		return err
+	}
+
+	tmp, err := ioutil.TempFile(filepath.Dir(c.path), filepath.Base(c.path)+".tmp")
+	if err != nil {
+		return err
+	}
+
+	if _, err := tmp.Write(b); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+
+	return os.Rename(tmp.Name(), c.path)
+}
@@ -0,0 +1,276 @@
+package bulk
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/marcosQuesada/finn"
+)
+
+const defaultConcurrency = 4
+
+// Creator creates accounts, as APIClient does
+type Creator interface {
+	Create(ctx context.Context, account *finn.Account, opts ...finn.CreateOption) (*finn.Account, error)
+}
+
+// Summary counts import outcomes
+type Summary struct {
+	Processed int
+	Created   int
+	Invalid   int
+	Failed    int
+	// Skipped counts rows already processed on a previous run, as recorded on checkpoint
+	Skipped int
+}
+
+// Importer creates accounts streamed from a Reader with bounded concurrency and rate limiting
+type Importer struct {
+	api            Creator
+	concurrency    int
+	interval       time.Duration
+	checkpoint     string
+	reporter       Reporter
+	organisationID string
+}
+
+// ImporterOption configures Importer
+type ImporterOption func(*Importer)
+
+// WithConcurrency bounds concurrent create requests, 4 by default
+func WithConcurrency(n int) ImporterOption {
+	return func(i *Importer) {
+		if n > 0 {
+			i.concurrency = n
+		}
+	}
+}
+
+// WithRateLimit caps create requests per second, unlimited by default
+func WithRateLimit(perSecond float64) ImporterOption {
+	return func(i *Importer) {
+		if perSecond > 0 {
+			i.interval = time.Duration(float64(time.Second) / perSecond)
+		}
+	}
+}
+
+// WithCheckpoint persists progress on path, rows up to the recorded line are skipped on next runs
+func WithCheckpoint(path string) ImporterOption {
+	return func(i *Importer) {
+		i.checkpoint = path
+	}
+}
+
+// WithReporter receives every row result
+func WithReporter(r Reporter) ImporterOption {
+	return func(i *Importer) {
+		i.reporter = r
+	}
+}
+
+// WithOrganisationID sets organisation id on rows without one
+func WithOrganisationID(id string) ImporterOption {
+	return func(i *Importer) {
+		i.organisationID = id
+	}
+}
+
+// NewImporter instantiates an importer
+func NewImporter(api Creator, opts ...ImporterOption) *Importer {
+	i := &Importer{
+		api:         api,
+		concurrency: defaultConcurrency,
+	}
+
+	for _, opt := range opts {
+		opt(i)
+	}
+
+	return i
+}
+
+// Run imports every row from r, rows are validated before being sent and each row result is reported,
+// rows without id get one derived from organisation id and line so resumed runs reuse it, created
+// requests carry the account id as idempotency key. Run stops on context cancellation, read or
+// checkpoint errors, rows not processed by then are picked again on next run
+func (i *Importer) Run(ctx context.Context, r Reader) (*Summary, error) {
+	cp, err := loadCheckpoint(i.checkpoint)
+	if err != nil {
+		return nil, fmt.Errorf("unexpected error loading checkpoint, error %w", err)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var limit <-chan time.Time
+	if i.interval > 0 {
+		t := time.NewTicker(i.interval)
+		defer t.Stop()
+		limit = t.C
+	}
+
+	s := &Summary{}
+	jobs := make(chan *Row)
+	results := make(chan *Result)
+	var readErr error
+	resume := cp.line
+	go func() {
+		defer close(jobs)
+		readErr = i.read(ctx, r, resume, cp, jobs, s)
+	}()
+
+	var wg sync.WaitGroup
+	for n := 0; n < i.concurrency; n++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for row := range jobs {
+				if res := i.process(ctx, row, limit); res != nil {
+					results <- res
+				}
+			}
+		}()
+	}
+
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	var runErr error
+	for res := range results {
+		if runErr != nil {
+			continue
+		}
+
+		if runErr = i.collect(res, cp, s); runErr != nil {
+			cancel()
+		}
+	}
+
+	if runErr != nil {
+		return s, runErr
+	}
+
+	if readErr != nil {
+		return s, readErr
+	}
+
+	return s, ctx.Err()
+}
+
+// read dispatches rows after resume line to jobs
+func (i *Importer) read(ctx context.Context, r Reader, resume int, cp *checkpoint, jobs chan<- *Row, s *Summary) error {
+	for {
+		row, err := r.Read()
+		if err == io.EOF {
+			return nil
+		}
+
+		if err != nil {
+			return fmt.Errorf("unexpected error reading source, error %w", err)
+		}
+
+		if row.Line <= resume {
+			s.Skipped++
+			continue
+		}
+
+		cp.dispatched(row.Line)
+		select {
+		case jobs <- row:
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// process validates and creates row account, nil is returned when row was interrupted by context
+// cancellation
+func (i *Importer) process(ctx context.Context, row *Row, limit <-chan time.Time) *Result {
+	res := &Result{Line: row.Line, Status: StatusInvalid, Err: row.Err}
+	if row.Err != nil {
+		return res
+	}
+
+	acc := i.prepare(row)
+	res.ID = acc.AccoundData.ID
+	if res.Err = acc.Validate(); res.Err != nil {
+		return res
+	}
+
+	if limit != nil {
+		select {
+		case <-limit:
+		case <-ctx.Done():
+			return nil
+		}
+	}
+
+	_, res.Err = i.api.Create(ctx, acc, finn.WithIdempotencyKey(res.ID))
+	if res.Err != nil && ctx.Err() != nil {
+		return nil
+	}
+
+	res.Status = StatusCreated
+	if res.Err != nil {
+		res.Status = StatusFailed
+	}
+
+	return res
+}
+
+// prepare fills account defaults
+func (i *Importer) prepare(row *Row) *finn.Account {
+	acc := row.Account
+	if acc == nil || acc.AccoundData == nil {
+		acc = &finn.Account{AccoundData: &finn.AccoundData{}}
+	}
+
+	d := acc.AccoundData
+	if d.Type == "" {
+		d.Type = "accounts"
+	}
+
+	if d.OrganisationID == "" {
+		d.OrganisationID = i.organisationID
+	}
+
+	if d.ID == "" {
+		if org, err := uuid.Parse(d.OrganisationID); err == nil {
+			d.ID = uuid.NewSHA1(org, []byte(fmt.Sprintf("line:%d", row.Line))).String()
+		}
+	}
+
+	return acc
+}
+
+// collect reports result, counts it and advances checkpoint
+func (i *Importer) collect(res *Result, cp *checkpoint, s *Summary) error {
+	s.Processed++
+	switch res.Status {
+	case StatusCreated:
+		s.Created++
+	case StatusInvalid:
+		s.Invalid++
+	case StatusFailed:
+		s.Failed++
+	}
+
+	if i.reporter != nil {
+		if err := i.reporter.Report(res); err != nil {
+			return fmt.Errorf("unexpected error reporting line %d, error %w", res.Line, err)
+		}
+	}
+
+	if err := cp.processed(res.Line); err != nil {
+		return fmt.Errorf("unexpected error saving checkpoint, error %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,298 @@
+package bulk
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/marcosQuesada/finn"
+	"github.com/marcosQuesada/finn/finntest"
+	"github.com/marcosQuesada/finn/http"
+)
+
+// fakeCreator records created accounts and concurrent calls
+type fakeCreator struct {
+	mu       sync.Mutex
+	created  map[string]int
+	inFlight int32
+	peak     int32
+	delay    time.Duration
+	onCreate func(n int)
+}
+
+func newFakeCreator() *fakeCreator {
+	return &fakeCreator{created: make(map[string]int)}
+}
+
+func (f *fakeCreator) Create(ctx context.Context, account *finn.Account, _ ...finn.CreateOption) (*finn.Account, error) {
+	n := atomic.AddInt32(&f.inFlight, 1)
+	defer atomic.AddInt32(&f.inFlight, -1)
+	for {
+		p := atomic.LoadInt32(&f.peak)
+		if n <= p || atomic.CompareAndSwapInt32(&f.peak, p, n) {
+			break
+		}
+	}
+
+	select {
+	case <-time.After(f.delay):
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	f.mu.Lock()
+	f.created[account.AccoundData.ID]++
+	total := len(f.created)
+	f.mu.Unlock()
+
+	if f.onCreate != nil {
+		f.onCreate(total)
+	}
+
+	return account, nil
+}
+
+func TestImporter_CreatesCSVRowsAndReportsEachRow(t *testing.T) {
+	srv := finntest.NewServer()
+	defer srv.Close()
+
+	org := uuid.New().String()
+	source := "Country,Sort Code,Code,BIC,Holder,Joint\n" +
+		"GB,400300,GBDSC,NWBKGB22,Jane|Doe,false\n" +
+		"GB,4003,GBDSC,NWBKGB22,John,false\n" +
+		"GB,400301,GBDSC,NWBKGB22,Ann,maybe\n" +
+		"GB,400302,GBDSC,NWBKGB22,Bob,true\n"
+	mapping := Mapping{
+		{Header: "Country", Field: "country"},
+		{Header: "Sort Code", Field: "bank_id"},
+		{Header: "Code", Field: "bank_id_code"},
+		{Header: "BIC", Field: "bic"},
+		{Header: "Holder", Field: "name"},
+		{Header: "Joint", Field: "joint_account"},
+	}
+
+	r, err := NewCSVReader(strings.NewReader(source), mapping)
+	if err != nil {
+		t.Fatalf("unexpected error creating reader, error %v", err)
+	}
+
+	report := &bytes.Buffer{}
+	api := finn.NewAPIClient(http.NewClientWithUrl(srv.BaseURL()))
+	imp := NewImporter(api, WithOrganisationID(org), WithReporter(NewCSVReport(report)), WithConcurrency(2))
+	s, err := imp.Run(context.Background(), r)
+	if err != nil {
+		t.Fatalf("unexpected error importing, error %v", err)
+	}
+
+	if s.Processed != 4 || s.Created != 2 || s.Invalid != 2 || s.Failed != 0 {
+		t.Errorf("unexpected summary, got %+v", s)
+	}
+
+	if srv.Len() != 2 {
+		t.Errorf("created accounts do not match, expected 2 got %d", srv.Len())
+	}
+
+	records, err := csv.NewReader(report).ReadAll()
+	if err != nil {
+		t.Fatalf("unexpected error reading report, error %v", err)
+	}
+
+	statuses := make(map[string]string)
+	for _, rec := range records[1:] {
+		statuses[rec[0]] = rec[2]
+	}
+
+	expected := map[string]string{"2": "created", "3": "invalid", "4": "invalid", "5": "created"}
+	for line, status := range expected {
+		if statuses[line] != status {
+			t.Errorf("line %s status does not match, expected %s got %s", line, status, statuses[line])
+		}
+	}
+}
+
+func TestImporter_BoundsConcurrencyAndRate(t *testing.T) {
+	api := newFakeCreator()
+	api.delay = 20 * time.Millisecond
+
+	r := NewJSONLReader(strings.NewReader(jsonlAccounts(12)))
+	start := time.Now()
+	s, err := NewImporter(api, WithConcurrency(3), WithRateLimit(200)).Run(context.Background(), r)
+	if err != nil {
+		t.Fatalf("unexpected error importing, error %v", err)
+	}
+
+	if s.Created != 12 {
+		t.Errorf("created accounts do not match, expected 12 got %d", s.Created)
+	}
+
+	if peak := atomic.LoadInt32(&api.peak); peak > 3 {
+		t.Errorf("concurrency not bounded, expected at most 3 got %d", peak)
+	}
+
+	if elapsed := time.Since(start); elapsed < 55*time.Millisecond {
+		t.Errorf("rate not limited, 12 creations at 200/s took %v", elapsed)
+	}
+}
+
+func TestImporter_ResumesFromCheckpoint(t *testing.T) {
+	dir, err := ioutil.TempDir("", "finn-bulk")
+	if err != nil {
+		t.Fatalf("unexpected error creating temp dir, error %v", err)
+	}
+	defer func() {
+		_ = os.RemoveAll(dir)
+	}()
+	path := filepath.Join(dir, "import.checkpoint")
+	source := jsonlAccounts(20)
+
+	api := newFakeCreator()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	api.onCreate = func(n int) {
+		if n == 7 {
+			cancel()
+		}
+	}
+
+	s, err := NewImporter(api, WithCheckpoint(path), WithConcurrency(3)).Run(ctx, NewJSONLReader(strings.NewReader(source)))
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("unexpected error type, expected canceled got %v", err)
+	}
+	first := s.Processed
+
+	api.onCreate = nil
+	s, err = NewImporter(api, WithCheckpoint(path), WithConcurrency(3)).Run(context.Background(), NewJSONLReader(strings.NewReader(source)))
+	if err != nil {
+		t.Fatalf("unexpected error resuming import, error %v", err)
+	}
+
+	if s.Skipped == 0 || s.Skipped > first {
+		t.Errorf("unexpected skipped rows, first run processed %d, skipped %d", first, s.Skipped)
+	}
+
+	if s.Skipped+s.Processed != 20 {
+		t.Errorf("rows not resumed, skipped %d processed %d", s.Skipped, s.Processed)
+	}
+
+	if len(api.created) != 20 {
+		t.Errorf("created accounts do not match, expected 20 got %d", len(api.created))
+	}
+}
+
+func TestImporter_DerivesStableIDs(t *testing.T) {
+	org := uuid.New().String()
+	source := `{"attributes": {"country": "GB", "bank_id": "400300", "bank_id_code": "GBDSC", "bic": "NWBKGB22"}}`
+
+	var ids []string
+	for i := 0; i < 2; i++ {
+		api := newFakeCreator()
+		if _, err := NewImporter(api, WithOrganisationID(org)).Run(context.Background(), NewJSONLReader(strings.NewReader(source))); err != nil {
+			t.Fatalf("unexpected error importing, error %v", err)
+		}
+
+		for id := range api.created {
+			ids = append(ids, id)
+		}
+	}
+
+	if len(ids) != 2 || ids[0] != ids[1] {
+		t.Errorf("derived ids are not stable, got %v", ids)
+	}
+}
+
+func TestExport_RoundTrip(t *testing.T) {
+	srv := finntest.NewServer()
+	defer srv.Close()
+
+	api := finn.NewAPIClient(http.NewClientWithUrl(srv.BaseURL()))
+	if _, err := NewImporter(api).Run(context.Background(), NewJSONLReader(strings.NewReader(jsonlAccounts(5)))); err != nil {
+		t.Fatalf("unexpected error importing, error %v", err)
+	}
+
+	q := finn.NewListOptions(finn.NewPagination(0, 2), nil)
+	jsonl := &bytes.Buffer{}
+	if n, err := Export(context.Background(), api, q, NewJSONLWriter(jsonl)); err != nil || n != 5 {
+		t.Fatalf("unexpected export result, exported %d error %v", n, err)
+	}
+
+	out := &bytes.Buffer{}
+	w, err := NewCSVWriter(out, DefaultMapping())
+	if err != nil {
+		t.Fatalf("unexpected error creating writer, error %v", err)
+	}
+
+	if n, err := Export(context.Background(), api, q, w); err != nil || n != 5 {
+		t.Fatalf("unexpected export result, exported %d error %v", n, err)
+	}
+
+	for name, r := range map[string]Reader{"jsonl": NewJSONLReader(jsonl), "csv": mustCSVReader(t, out)} {
+		var rows int
+		for {
+			row, err := r.Read()
+			if err != nil {
+				break
+			}
+
+			if row.Err != nil {
+				t.Errorf("unexpected %s row error, error %v", name, row.Err)
+				continue
+			}
+
+			stored, err := api.Fetch(context.Background(), row.Account.AccoundData.ID)
+			if err != nil {
+				t.Fatalf("unexpected error fetching exported account, error %v", err)
+			}
+
+			if got, want := row.Account.AccoundData.Attributes.BankID, stored.AccoundData.Attributes.BankID; got != want {
+				t.Errorf("%s bank id does not match, expected %s got %s", name, want, got)
+			}
+			rows++
+		}
+
+		if rows != 5 {
+			t.Errorf("%s exported rows do not match, expected 5 got %d", name, rows)
+		}
+	}
+}
+
+func TestNewCSVReader_RejectsUnknownFields(t *testing.T) {
+	_, err := NewCSVReader(strings.NewReader("colour\nred\n"), Mapping{{Header: "colour", Field: "colour"}})
+	if !errors.Is(err, ErrUnknownField) {
+		t.Errorf("unexpected error type, expected unknown field got %v", err)
+	}
+}
+
+// jsonlAccounts builds n valid GB account lines
+func jsonlAccounts(n int) string {
+	org := uuid.New().String()
+	var b strings.Builder
+	for i := 0; i < n; i++ {
+		_, _ = fmt.Fprintf(&b, `{"data": {"type": "accounts", "id": "%s", "organisation_id": "%s", "attributes": {"country": "GB", "bank_id": "%06d", "bank_id_code": "GBDSC", "bic": "NWBKGB22"}}}`+"\n",
+			uuid.New().String(), org, 400300+i)
+		if i%5 == 0 {
+			b.WriteString("\n")
+		}
+	}
+
+	return b.String()
+}
+
+func mustCSVReader(t *testing.T, r *bytes.Buffer) *CSVReader {
+	cr, err := NewCSVReader(r, DefaultMapping())
+	if err != nil {
+		t.Fatalf("unexpected error creating reader, error %v", err)
+	}
+
+	return cr
+}
@@ -0,0 +1,126 @@
+package bulk
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/marcosQuesada/finn"
+)
+
+// ListSeparator splits multi valued fields, as name or alternative_names, on CSV cells
+const ListSeparator = "|"
+
+// ErrUnknownField happens when a mapping column points to a not supported account field
+var ErrUnknownField = errors.New("unknown account field")
+
+// Column maps a CSV header to an account field, fields are named as their json attribute
+type Column struct {
+	Header string
+	Field  string
+}
+
+// Mapping declares CSV columns, in output order on export
+type Mapping []Column
+
+// field reads and writes an account field from its CSV representation
+type field struct {
+	get func(d *finn.AccoundData) string
+	set func(d *finn.AccoundData, v string) error
+}
+
+// fieldNames keeps supported fields in declaration order
+var fieldNames = []string{
+	"id", "organisation_id", "version", "country", "base_currency", "bank_id", "bank_id_code", "bic",
+	"account_number", "iban", "name", "alternative_names", "account_classification", "joint_account",
+	"account_matching_opt_out", "secondary_identification", "switched", "status", "customer_id",
+}
+
+var fields = map[string]*field{
+	"id": {
+		get: func(d *finn.AccoundData) string { return d.ID },
+		set: func(d *finn.AccoundData, v string) error { d.ID = v; return nil },
+	},
+	"organisation_id": {
+		get: func(d *finn.AccoundData) string { return d.OrganisationID },
+		set: func(d *finn.AccoundData, v string) error { d.OrganisationID = v; return nil },
+	},
+	"version": {
+		get: func(d *finn.AccoundData) string { return strconv.Itoa(d.Version) },
+		set: func(d *finn.AccoundData, v string) (err error) { d.Version, err = strconv.Atoi(v); return err },
+	},
+	"country":                  stringField(func(a *finn.Attributes) *string { return &a.Country }),
+	"base_currency":            stringField(func(a *finn.Attributes) *string { return &a.BaseCurrency }),
+	"bank_id":                  stringField(func(a *finn.Attributes) *string { return &a.BankID }),
+	"bank_id_code":             stringField(func(a *finn.Attributes) *string { return &a.BankIDCode }),
+	"bic":                      stringField(func(a *finn.Attributes) *string { return &a.Bic }),
+	"account_number":           stringField(func(a *finn.Attributes) *string { return &a.AccountNumber }),
+	"iban":                     stringField(func(a *finn.Attributes) *string { return &a.Iban }),
+	"name":                     listField(func(a *finn.Attributes) *[]string { return &a.Name }),
+	"alternative_names":        listField(func(a *finn.Attributes) *[]string { return &a.AlternativeNames }),
+	"account_classification":   stringField(func(a *finn.Attributes) *string { return &a.AccountClassification }),
+	"joint_account":            boolField(func(a *finn.Attributes) *bool { return &a.JointAccount }),
+	"account_matching_opt_out": boolField(func(a *finn.Attributes) *bool { return &a.AccountMatchingOptOut }),
+	"secondary_identification": stringField(func(a *finn.Attributes) *string { return &a.SecondaryID }),
+	"switched":                 boolField(func(a *finn.Attributes) *bool { return &a.Switched }),
+	"status":                   stringField(func(a *finn.Attributes) *string { return &a.Status }),
+	"customer_id":              stringField(func(a *finn.Attributes) *string { return &a.CustomerID }),
+}
+
+// DefaultMapping maps every supported field to a column named as the field
+func DefaultMapping() Mapping {
+	m := make(Mapping, len(fieldNames))
+	for i, f := range fieldNames {
+		m[i] = Column{Header: f, Field: f}
+	}
+
+	return m
+}
+
+// validate checks every column points to a supported field
+func (m Mapping) validate() error {
+	for _, c := range m {
+		if _, ok := fields[c.Field]; !ok {
+			return fmt.Errorf("column %s field %s, error %w", c.Header, c.Field, ErrUnknownField)
+		}
+	}
+
+	return nil
+}
+
+// attributes returns account attributes, creating them when missing
+func attributes(d *finn.AccoundData) *finn.Attributes {
+	if d.Attributes == nil {
+		d.Attributes = &finn.Attributes{}
+	}
+
+	return d.Attributes
+}
+
+func stringField(ref func(a *finn.Attributes) *string) *field {
+	return &field{
+		get: func(d *finn.AccoundData) string { return *ref(attributes(d)) },
+		set: func(d *finn.AccoundData, v string) error { *ref(attributes(d)) = v; return nil },
+	}
+}
+
+func listField(ref func(a *finn.Attributes) *[]string) *field {
+	return &field{
+		get: func(d *finn.AccoundData) string { return strings.Join(*ref(attributes(d)), ListSeparator) },
+		set: func(d *finn.AccoundData, v string) error {
+			*ref(attributes(d)) = strings.Split(v, ListSeparator)
+			return nil
+		},
+	}
+}
+
+func boolField(ref func(a *finn.Attributes) *bool) *field {
+	return &field{
+		get: func(d *finn.AccoundData) string { return strconv.FormatBool(*ref(attributes(d))) },
+		set: func(d *finn.AccoundData, v string) (err error) {
+			*ref(attributes(d)), err = strconv.ParseBool(v)
+			return err
+		},
+	}
+}
@@ -0,0 +1,160 @@
+package bulk
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/marcosQuesada/finn"
+)
+
+const maxLineSize = 1 << 20
+
+// Row holds a parsed source row, Err reports a row that could not be parsed
+type Row struct {
+	// Line is the 1-based CSV record or JSONL line number, it identifies the row on reports and checkpoints
+	Line    int
+	Account *finn.Account
+	Err     error
+}
+
+// Reader streams account rows, io.EOF is returned once source is consumed,
+// any other error aborts the stream
+type Reader interface {
+	Read() (*Row, error)
+}
+
+// CSVReader streams accounts from CSV, first record holds column headers
+type CSVReader struct {
+	r       *csv.Reader
+	line    int
+	columns map[int]*field
+	headers []string
+}
+
+// NewCSVReader reads header record and binds mapped columns, headers without mapping are ignored
+func NewCSVReader(r io.Reader, m Mapping) (*CSVReader, error) {
+	if err := m.validate(); err != nil {
+		return nil, err
+	}
+
+	cr := csv.NewReader(r)
+	headers, err := cr.Read()
+	if err != nil {
+		return nil, fmt.Errorf("unexpected error reading csv header, error %w", err)
+	}
+
+	byHeader := make(map[string]*field, len(m))
+	for _, c := range m {
+		byHeader[c.Header] = fields[c.Field]
+	}
+
+	columns := make(map[int]*field)
+	for i, h := range headers {
+		if f, ok := byHeader[strings.TrimSpace(h)]; ok {
+			columns[i] = f
+		}
+	}
+
+	return &CSVReader{
+		r:       cr,
+		line:    1,
+		columns: columns,
+		headers: headers,
+	}, nil
+}
+
+// Read parses next record, malformed records are returned as row errors
+func (c *CSVReader) Read() (*Row, error) {
+	record, err := c.r.Read()
+	if err == io.EOF {
+		return nil, err
+	}
+	c.line++
+
+	row := &Row{Line: c.line}
+	var parseErr *csv.ParseError
+	if errors.As(err, &parseErr) {
+		row.Err = err
+		return row, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	d := &finn.AccoundData{Attributes: &finn.Attributes{}}
+	for i, v := range record {
+		f, ok := c.columns[i]
+		if !ok || v == "" {
+			continue
+		}
+
+		if err := f.set(d, v); err != nil {
+			row.Err = fmt.Errorf("column %s value %s, error %v", c.headers[i], v, err)
+			return row, nil
+		}
+	}
+	row.Account = &finn.Account{AccoundData: d}
+
+	return row, nil
+}
+
+// JSONLReader streams accounts from JSON lines, each line holds an account document or bare account data
+type JSONLReader struct {
+	s    *bufio.Scanner
+	line int
+}
+
+// NewJSONLReader instantiates a JSON lines reader
+func NewJSONLReader(r io.Reader) *JSONLReader {
+	s := bufio.NewScanner(r)
+	s.Buffer(make([]byte, 64*1024), maxLineSize)
+
+	return &JSONLReader{s: s}
+}
+
+// Read decodes next non blank line
+func (j *JSONLReader) Read() (*Row, error) {
+	for j.s.Scan() {
+		j.line++
+		line := strings.TrimSpace(j.s.Text())
+		if line == "" {
+			continue
+		}
+
+		row := &Row{Line: j.line}
+		row.Account, row.Err = decodeAccount([]byte(line))
+
+		return row, nil
+	}
+
+	if err := j.s.Err(); err != nil {
+		return nil, err
+	}
+
+	return nil, io.EOF
+}
+
+// decodeAccount decodes an account document, falling back to bare account data
+func decodeAccount(b []byte) (*finn.Account, error) {
+	acc := &finn.Account{}
+	if err := json.Unmarshal(b, acc); err != nil {
+		return nil, err
+	}
+
+	if acc.AccoundData != nil {
+		return acc, nil
+	}
+
+	acc.AccoundData = &finn.AccoundData{}
+	if err := json.Unmarshal(b, acc.AccoundData); err != nil {
+		return nil, err
+	}
+
+	return acc, nil
+}
@@ -0,0 +1,65 @@
+package bulk
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"sync"
+)
+
+// Status defines a row import outcome
+type Status string
+
+const (
+	// StatusCreated flags created accounts, including replayed creations
+	StatusCreated Status = "created"
+	// StatusInvalid flags rows that could not be parsed or failed validation, they are not sent
+	StatusInvalid Status = "invalid"
+	// StatusFailed flags rows rejected by the api or failing on transport
+	StatusFailed Status = "failed"
+)
+
+// Result describes a row import outcome
+type Result struct {
+	Line   int
+	ID     string
+	Status Status
+	Err    error
+}
+
+// Reporter receives every row result, results come in completion order
+type Reporter interface {
+	Report(r *Result) error
+}
+
+// CSVReport writes results as CSV records, flushed on each result so the report survives crashes
+type CSVReport struct {
+	mu     sync.Mutex
+	w      *csv.Writer
+	header bool
+}
+
+// NewCSVReport instantiates a CSV report
+func NewCSVReport(w io.Writer) *CSVReport {
+	return &CSVReport{w: csv.NewWriter(w)}
+}
+
+// Report writes result record
+func (c *CSVReport) Report(r *Result) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.header {
+		c.header = true
+		_ = c.w.Write([]string{"line", "id", "status", "error"})
+	}
+
+	var msg string
+	if r.Err != nil {
+		msg = r.Err.Error()
+	}
+	_ = c.w.Write([]string{strconv.Itoa(r.Line), r.ID, string(r.Status), msg})
+	c.w.Flush()
+
+	return c.w.Error()
+}
@@ -0,0 +1,117 @@
+package bulk
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+
+	"github.com/marcosQuesada/finn"
+)
+
+// Writer writes accounts on a target format, Flush must be called once every account is written
+type Writer interface {
+	Write(d *finn.AccoundData) error
+	Flush() error
+}
+
+// Lister lists accounts following every page, as APIClient does
+type Lister interface {
+	ListAll(ctx context.Context, q finn.Query) *finn.Iterator
+}
+
+// Export writes every account matching query, returns written accounts
+func Export(ctx context.Context, l Lister, q finn.Query, w Writer) (int, error) {
+	var n int
+	it := l.ListAll(ctx, q).WithPrefetch()
+	for it.Next() {
+		if err := w.Write(it.Account()); err != nil {
+			return n, err
+		}
+		n++
+	}
+
+	if err := it.Err(); err != nil {
+		return n, err
+	}
+
+	return n, w.Flush()
+}
+
+// CSVWriter writes accounts as CSV, header record is written before first account
+type CSVWriter struct {
+	w       *csv.Writer
+	mapping Mapping
+	header  bool
+}
+
+// NewCSVWriter instantiates a CSV writer with mapping columns
+func NewCSVWriter(w io.Writer, m Mapping) (*CSVWriter, error) {
+	if err := m.validate(); err != nil {
+		return nil, err
+	}
+
+	return &CSVWriter{
+		w:       csv.NewWriter(w),
+		mapping: m,
+	}, nil
+}
+
+// Write writes account as a CSV record
+func (c *CSVWriter) Write(d *finn.AccoundData) error {
+	if err := c.writeHeader(); err != nil {
+		return err
+	}
+
+	record := make([]string, len(c.mapping))
+	for i, col := range c.mapping {
+		record[i] = fields[col.Field].get(d)
+	}
+
+	return c.w.Write(record)
+}
+
+// Flush writes buffered records, header is written even when no account was exported
+func (c *CSVWriter) Flush() error {
+	if err := c.writeHeader(); err != nil {
+		return err
+	}
+	c.w.Flush()
+
+	return c.w.Error()
+}
+
+// writeHeader writes header record once
+func (c *CSVWriter) writeHeader() error {
+	if c.header {
+		return nil
+	}
+	c.header = true
+
+	headers := make([]string, len(c.mapping))
+	for i, col := range c.mapping {
+		headers[i] = col.Header
+	}
+
+	return c.w.Write(headers)
+}
+
+// JSONLWriter writes an account document per line
+type JSONLWriter struct {
+	enc *json.Encoder
+}
+
+// NewJSONLWriter instantiates a JSON lines writer
+func NewJSONLWriter(w io.Writer) *JSONLWriter {
+	return &JSONLWriter{enc: json.NewEncoder(w)}
+}
+
+// Write writes account document line
+func (j *JSONLWriter) Write(d *finn.AccoundData) error {
+	return j.enc.Encode(&finn.Account{AccoundData: d})
+}
+
+// Flush is a no-op, lines are written as they come
+func (j *JSONLWriter) Flush() error {
+	return nil
+}
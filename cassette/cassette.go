@@ -0,0 +1,78 @@
+package cassette
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+)
+
+// Version identifies cassette file format
+const Version = 1
+
+// ErrUnsupportedVersion happens loading cassettes recorded with another format version
+var ErrUnsupportedVersion = errors.New("unsupported cassette version")
+
+// Cassette holds recorded http interactions
+type Cassette struct {
+	Version      int            `json:"version"`
+	Interactions []*Interaction `json:"interactions"`
+}
+
+// Interaction defines a recorded request and its response
+type Interaction struct {
+	Request  *Request  `json:"request"`
+	Response *Response `json:"response"`
+}
+
+// Request defines a recorded http request
+type Request struct {
+	Method string      `json:"method"`
+	URL    string      `json:"url"`
+	Header http.Header `json:"header,omitempty"`
+	Body   string      `json:"body,omitempty"`
+}
+
+// Response defines a recorded http response
+type Response struct {
+	StatusCode int         `json:"status_code"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       string      `json:"body,omitempty"`
+}
+
+// New instantiates an empty cassette
+func New() *Cassette {
+	return &Cassette{
+		Version: Version,
+	}
+}
+
+// Load reads a cassette file
+func Load(path string) (*Cassette, error) {
+	raw, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &Cassette{}
+	if err := json.Unmarshal(raw, c); err != nil {
+		return nil, fmt.Errorf("unexpected error decoding cassette %s, error %w", path, err)
+	}
+
+	if c.Version != Version {
+		return nil, fmt.Errorf("cassette %s version %d, error %w", path, c.Version, ErrUnsupportedVersion)
+	}
+
+	return c, nil
+}
+
+// Save writes cassette file
+func (c *Cassette) Save(path string) error {
+	raw, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(path, raw, 0644)
+}
@@ -0,0 +1,230 @@
+package cassette
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+
+	"github.com/marcosQuesada/finn/internal/redact"
+	"github.com/marcosQuesada/finn/internal/reqbody"
+)
+
+// ErrInteractionNotFound happens on replay when no recorded interaction matches request
+var ErrInteractionNotFound = errors.New("interaction not found")
+
+// Mode defines recorder behaviour
+type Mode int
+
+const (
+	// ModeRecord forwards requests to transport and captures interactions
+	ModeRecord Mode = iota
+	// ModeReplay replies recorded interactions without network access
+	ModeReplay
+)
+
+// DefaultHeaderRedaction masks credentials headers
+var DefaultHeaderRedaction = []string{"Authorization", "Signature", "Cookie", "Set-Cookie"}
+
+// DefaultBodyRedaction masks account personal identifiable information
+var DefaultBodyRedaction = []string{
+	"data.attributes.private_identification",
+	"data.attributes.organisation_identification.actors",
+	"data.attributes.name",
+	"data.attributes.alternative_names",
+}
+
+// Matcher checks if a recorded interaction matches request, body has been already redacted
+type Matcher func(req *http.Request, body []byte, i *Interaction) bool
+
+// StrictMatcher matches method, path, query and body
+func StrictMatcher(req *http.Request, body []byte, i *Interaction) bool {
+	u, err := url.Parse(i.Request.URL)
+	if err != nil {
+		return false
+	}
+
+	return req.Method == i.Request.Method &&
+		req.URL.Path == u.Path &&
+		req.URL.RawQuery == u.RawQuery &&
+		string(body) == i.Request.Body
+}
+
+// LenientMatcher matches method only, interactions are replayed in recorded order,
+// it enables replaying requests that carry generated identifiers
+func LenientMatcher(req *http.Request, _ []byte, i *Interaction) bool {
+	return req.Method == i.Request.Method
+}
+
+// Option configures Recorder
+type Option func(*Recorder)
+
+// WithTransport sets recording transport, http.DefaultTransport by default
+func WithTransport(rt http.RoundTripper) Option {
+	return func(r *Recorder) {
+		r.transport = rt
+	}
+}
+
+// WithMatcher sets replay matcher, StrictMatcher by default
+func WithMatcher(m Matcher) Option {
+	return func(r *Recorder) {
+		r.matcher = m
+	}
+}
+
+// WithHeaderRedaction replaces redacted header names
+func WithHeaderRedaction(names ...string) Option {
+	return func(r *Recorder) {
+		r.headers = names
+	}
+}
+
+// WithBodyRedaction replaces redacted json body paths, as data.attributes.iban
+func WithBodyRedaction(paths ...string) Option {
+	return func(r *Recorder) {
+		r.paths = paths
+	}
+}
+
+// Recorder is an http.RoundTripper that records interactions into a cassette file
+// or replays them deterministically
+type Recorder struct {
+	mutex     sync.Mutex
+	path      string
+	mode      Mode
+	cassette  *Cassette
+	used      []bool
+	transport http.RoundTripper
+	matcher   Matcher
+	headers   []string
+	paths     []string
+}
+
+// NewRecorder instantiates a recorder on cassette path, replay mode loads cassette file
+func NewRecorder(path string, mode Mode, opts ...Option) (*Recorder, error) {
+	r := &Recorder{
+		path:      path,
+		mode:      mode,
+		cassette:  New(),
+		transport: http.DefaultTransport,
+		matcher:   StrictMatcher,
+		headers:   DefaultHeaderRedaction,
+		paths:     DefaultBodyRedaction,
+	}
+
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	if mode == ModeReplay {
+		c, err := Load(path)
+		if err != nil {
+			return nil, err
+		}
+		r.cassette = c
+		r.used = make([]bool, len(c.Interactions))
+	}
+
+	return r, nil
+}
+
+// RoundTrip records or replays request
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, err := reqbody.Read(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if r.mode == ModeReplay {
+		return r.replay(req, body)
+	}
+
+	return r.record(req, body)
+}
+
+// Stop saves cassette file when recording
+func (r *Recorder) Stop() error {
+	if r.mode != ModeRecord {
+		return nil
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	return r.cassette.Save(r.path)
+}
+
+// record forwards request and captures redacted interaction
+func (r *Recorder) record(req *http.Request, body []byte) (*http.Response, error) {
+	resp, err := r.transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	respBody, err := ioutil.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(respBody))
+
+	i := &Interaction{
+		Request: &Request{
+			Method: req.Method,
+			URL:    req.URL.String(),
+			Header: redact.Header(req.Header, r.headers),
+			Body:   string(redact.JSON(body, r.paths)),
+		},
+		Response: &Response{
+			StatusCode: resp.StatusCode,
+			Header:     redact.Header(resp.Header, r.headers),
+			Body:       string(redact.JSON(respBody, r.paths)),
+		},
+	}
+
+	r.mutex.Lock()
+	r.cassette.Interactions = append(r.cassette.Interactions, i)
+	r.mutex.Unlock()
+
+	return resp, nil
+}
+
+// replay replies first unused matching interaction
+func (r *Recorder) replay(req *http.Request, body []byte) (*http.Response, error) {
+	body = redact.JSON(body, r.paths)
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for n, i := range r.cassette.Interactions {
+		if r.used[n] || !r.matcher(req, body, i) {
+			continue
+		}
+		r.used[n] = true
+
+		header := i.Response.Header.Clone()
+		if header == nil {
+			header = make(http.Header)
+		}
+		header.Set("Content-Length", strconv.Itoa(len(i.Response.Body)))
+
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", i.Response.StatusCode, http.StatusText(i.Response.StatusCode)),
+			StatusCode:    i.Response.StatusCode,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        header,
+			Body:          ioutil.NopCloser(bytes.NewReader([]byte(i.Response.Body))),
+			ContentLength: int64(len(i.Response.Body)),
+			Request:       req,
+		}, nil
+	}
+
+	return nil, fmt.Errorf("%s %s, error %w", req.Method, req.URL.String(), ErrInteractionNotFound)
+}
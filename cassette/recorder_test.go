@@ -0,0 +1,195 @@
+package cassette
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	nethttp "net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/marcosQuesada/finn"
+	"github.com/marcosQuesada/finn/finntest"
+	"github.com/marcosQuesada/finn/http"
+)
+
+func TestRecorder_RecordsAndReplaysInteractionsOffline(t *testing.T) {
+	path, cleanup := tempCassette(t)
+	defer cleanup()
+	acc := newAccount()
+
+	srv := finntest.NewServer()
+	rec, err := NewRecorder(path, ModeRecord)
+	if err != nil {
+		t.Fatalf("unexpected error creating recorder, error %v", err)
+	}
+
+	api := finn.NewAPIClient(http.NewClientWithUrl(srv.BaseURL(), http.WithTransport(rec)))
+	if _, err := api.Create(context.Background(), acc); err != nil {
+		t.Fatalf("unexpected error creating account, error %v", err)
+	}
+
+	if _, err := api.Fetch(context.Background(), acc.AccoundData.ID); err != nil {
+		t.Fatalf("unexpected error fetching account, error %v", err)
+	}
+
+	if err := rec.Stop(); err != nil {
+		t.Fatalf("unexpected error saving cassette, error %v", err)
+	}
+
+	baseURL := srv.BaseURL()
+	srv.Close()
+
+	replay, err := NewRecorder(path, ModeReplay)
+	if err != nil {
+		t.Fatalf("unexpected error loading cassette, error %v", err)
+	}
+
+	api = finn.NewAPIClient(http.NewClientWithUrl(baseURL, http.WithTransport(replay)))
+	if _, err := api.Create(context.Background(), acc); err != nil {
+		t.Fatalf("unexpected error replaying create, error %v", err)
+	}
+
+	fetched, err := api.Fetch(context.Background(), acc.AccoundData.ID)
+	if err != nil {
+		t.Fatalf("unexpected error replaying fetch, error %v", err)
+	}
+
+	if got, want := fetched.AccoundData.ID, acc.AccoundData.ID; got != want {
+		t.Errorf("replayed account id does not match, expected %s got %s", want, got)
+	}
+
+	_, err = api.Fetch(context.Background(), acc.AccoundData.ID)
+	if !errors.Is(err, ErrInteractionNotFound) {
+		t.Errorf("unexpected error type, expected interaction not found got %v", err)
+	}
+}
+
+func TestRecorder_RedactsPersonalInformation(t *testing.T) {
+	path, cleanup := tempCassette(t)
+	defer cleanup()
+	srv := finntest.NewServer()
+	defer srv.Close()
+
+	rec, err := NewRecorder(path, ModeRecord, WithHeaderRedaction("Authorization"))
+	if err != nil {
+		t.Fatalf("unexpected error creating recorder, error %v", err)
+	}
+
+	auth := func(next http.RoundTripFunc) http.RoundTripFunc {
+		return func(req *nethttp.Request) (*nethttp.Response, error) {
+			req.Header.Set("Authorization", "Bearer secretToken")
+			return next(req)
+		}
+	}
+
+	acc := newAccount()
+	acc.AccoundData.Attributes.PrivateID = &finn.PrivateIdentification{
+		BirthDate:      "2017-07-23",
+		Identification: "13YH458762",
+	}
+	acc.AccoundData.Attributes.Name = []string{"Samantha Holder"}
+
+	api := finn.NewAPIClient(http.NewClientWithUrl(srv.BaseURL(), http.WithTransport(rec), http.WithMiddleware(auth)))
+	if _, err := api.Create(context.Background(), acc); err != nil {
+		t.Fatalf("unexpected error creating account, error %v", err)
+	}
+
+	if err := rec.Stop(); err != nil {
+		t.Fatalf("unexpected error saving cassette, error %v", err)
+	}
+
+	raw, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("unexpected error reading cassette, error %v", err)
+	}
+
+	for _, secret := range []string{"secretToken", "2017-07-23", "13YH458762", "Samantha Holder"} {
+		if strings.Contains(string(raw), secret) {
+			t.Errorf("cassette holds unredacted value %s", secret)
+		}
+	}
+}
+
+func TestRecorder_StrictMatcherRejectsDifferentRequests(t *testing.T) {
+	path, cleanup := tempCassette(t)
+	defer cleanup()
+	c := New()
+	c.Interactions = []*Interaction{{
+		Request:  &Request{Method: "GET", URL: "http://fake/v1/organisation/accounts/1"},
+		Response: &Response{StatusCode: 200, Body: `{"data": {"id": "1"}}`},
+	}}
+	if err := c.Save(path); err != nil {
+		t.Fatalf("unexpected error saving cassette, error %v", err)
+	}
+
+	for _, test := range []struct {
+		matcher Matcher
+		found   bool
+	}{
+		{matcher: StrictMatcher, found: false},
+		{matcher: LenientMatcher, found: true},
+	} {
+		replay, err := NewRecorder(path, ModeReplay, WithMatcher(test.matcher))
+		if err != nil {
+			t.Fatalf("unexpected error loading cassette, error %v", err)
+		}
+
+		api := finn.NewAPIClient(http.NewClientWithUrl(mustParse(t, "http://fake/"), http.WithTransport(replay)))
+		_, err = api.Fetch(context.Background(), "2")
+		if test.found && err != nil {
+			t.Errorf("unexpected error replaying fetch, error %v", err)
+		}
+
+		if !test.found && !errors.Is(err, ErrInteractionNotFound) {
+			t.Errorf("unexpected error type, expected interaction not found got %v", err)
+		}
+	}
+}
+
+func TestLoadRejectsUnsupportedVersions(t *testing.T) {
+	path, cleanup := tempCassette(t)
+	defer cleanup()
+	if err := ioutil.WriteFile(path, []byte(`{"version": 99}`), 0644); err != nil {
+		t.Fatalf("unexpected error writing cassette, error %v", err)
+	}
+
+	if _, err := Load(path); !errors.Is(err, ErrUnsupportedVersion) {
+		t.Errorf("unexpected error type, expected unsupported version got %v", err)
+	}
+}
+
+func tempCassette(t *testing.T) (string, func()) {
+	dir, err := ioutil.TempDir("", "cassette")
+	if err != nil {
+		t.Fatalf("unexpected error creating temp dir, error %v", err)
+	}
+
+	return filepath.Join(dir, "accounts.json"), func() {
+		_ = os.RemoveAll(dir)
+	}
+}
+
+func mustParse(t *testing.T, raw string) *url.URL {
+	u, err := url.Parse(raw)
+	if err != nil {
+		t.Fatalf("unexpected error parsing url, error %v", err)
+	}
+
+	return u
+}
+
+func newAccount() *finn.Account {
+	return &finn.Account{
+		AccoundData: &finn.AccoundData{
+			Type:           "accounts",
+			ID:             uuid.New().String(),
+			OrganisationID: uuid.New().String(),
+			Attributes:     &finn.Attributes{Country: "GB"},
+		},
+	}
+}
@@ -0,0 +1,478 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/marcosQuesada/finn"
+)
+
+const updateAttempts = 3
+
+// filterFields maps --filter field names to filter setters
+var filterFields = map[string]func(*finn.Filter, ...string) *finn.Filter{
+	"bank_id":        (*finn.Filter).BankID,
+	"bank_id_code":   (*finn.Filter).BankIDCode,
+	"account_number": (*finn.Filter).AccountNumber,
+	"iban":           (*finn.Filter).Iban,
+	"country":        (*finn.Filter).Country,
+	"customer_id":    (*finn.Filter).CustomerID,
+}
+
+// stringList collects repeated flag values
+type stringList []string
+
+// String joins collected values
+func (l *stringList) String() string {
+	return strings.Join(*l, ",")
+}
+
+// Set appends a value
+func (l *stringList) Set(v string) error {
+	*l = append(*l, v)
+
+	return nil
+}
+
+// accountFlags declares account attributes as flags
+type accountFlags struct {
+	file           string
+	id             string
+	organisationID string
+	attributes     finn.Attributes
+	names          stringList
+}
+
+// register declares account flags
+func (f *accountFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&f.file, "file", "", "JSON account document, - reads stdin")
+	fs.StringVar(&f.organisationID, "organisation-id", "", "organisation id")
+	fs.StringVar(&f.attributes.Country, "country", "", "country code")
+	fs.StringVar(&f.attributes.BaseCurrency, "base-currency", "", "base currency code")
+	fs.StringVar(&f.attributes.BankID, "bank-id", "", "bank id")
+	fs.StringVar(&f.attributes.BankIDCode, "bank-id-code", "", "bank id code")
+	fs.StringVar(&f.attributes.Bic, "bic", "", "bic")
+	fs.StringVar(&f.attributes.AccountNumber, "account-number", "", "account number")
+	fs.StringVar(&f.attributes.Iban, "iban", "", "iban")
+	fs.StringVar(&f.attributes.CustomerID, "customer-id", "", "customer id")
+	fs.StringVar(&f.attributes.AccountClassification, "classification", "", "account classification")
+	fs.StringVar(&f.attributes.Status, "status", "", "account status")
+	fs.Var(&f.names, "name", "account holder name, repeatable")
+}
+
+// account reads account from file or builds it from flags
+func (f *accountFlags) account(e *env) (*finn.Account, error) {
+	if f.file != "" {
+		return readAccount(f.file, e.stdin)
+	}
+
+	attr := f.attributes
+	attr.Name = f.names
+
+	return &finn.Account{
+		AccoundData: &finn.AccoundData{
+			ID:             f.id,
+			OrganisationID: f.organisationID,
+			Attributes:     &attr,
+		},
+	}, nil
+}
+
+// apply overlays attributes set by flags
+func (f *accountFlags) apply(fs *flag.FlagSet, attr *finn.Attributes) {
+	fs.Visit(func(fl *flag.Flag) {
+		switch fl.Name {
+		case "country":
+			attr.Country = f.attributes.Country
+		case "base-currency":
+			attr.BaseCurrency = f.attributes.BaseCurrency
+		case "bank-id":
+			attr.BankID = f.attributes.BankID
+		case "bank-id-code":
+			attr.BankIDCode = f.attributes.BankIDCode
+		case "bic":
+			attr.Bic = f.attributes.Bic
+		case "account-number":
+			attr.AccountNumber = f.attributes.AccountNumber
+		case "iban":
+			attr.Iban = f.attributes.Iban
+		case "customer-id":
+			attr.CustomerID = f.attributes.CustomerID
+		case "classification":
+			attr.AccountClassification = f.attributes.AccountClassification
+		case "status":
+			attr.Status = f.attributes.Status
+		case "name":
+			attr.Name = f.names
+		}
+	})
+}
+
+// readAccount decodes an account document, bare account data is accepted too
+func readAccount(file string, stdin io.Reader) (*finn.Account, error) {
+	raw, err := readFile(file, stdin)
+	if err != nil {
+		return nil, err
+	}
+
+	return decodeAccount(file, raw)
+}
+
+// readFile reads a json document from file, - reads it from stdin
+func readFile(file string, stdin io.Reader) (json.RawMessage, error) {
+	r := stdin
+	if file != "-" {
+		f, err := os.Open(file)
+		if err != nil {
+			return nil, err
+		}
+		defer func() {
+			_ = f.Close()
+		}()
+		r = f
+	}
+
+	var raw json.RawMessage
+	if err := json.NewDecoder(r).Decode(&raw); err != nil {
+		return nil, fmt.Errorf("account file %s, error %v", file, err)
+	}
+
+	return raw, nil
+}
+
+// decodeAccount decodes an account document, bare account data is accepted too
+func decodeAccount(file string, raw json.RawMessage) (*finn.Account, error) {
+	acc := &finn.Account{}
+	if err := json.Unmarshal(raw, acc); err != nil {
+		return nil, fmt.Errorf("account file %s, error %v", file, err)
+	}
+
+	if acc.AccoundData == nil {
+		acc.AccoundData = &finn.AccoundData{}
+		if err := json.Unmarshal(raw, acc.AccoundData); err != nil {
+			return nil, fmt.Errorf("account file %s, error %v", file, err)
+		}
+	}
+
+	return acc, nil
+}
+
+// updateFile holds an update account file, declared fields are kept to tell them from zero values
+type updateFile struct {
+	account    *finn.Account
+	hasVersion bool
+	attributes json.RawMessage
+}
+
+// readUpdateFile reads an update account file
+func readUpdateFile(file string, stdin io.Reader) (*updateFile, error) {
+	raw, err := readFile(file, stdin)
+	if err != nil {
+		return nil, err
+	}
+
+	acc, err := decodeAccount(file, raw)
+	if err != nil {
+		return nil, err
+	}
+
+	doc := &struct {
+		Data map[string]json.RawMessage `json:"data"`
+	}{}
+	if err := json.Unmarshal(raw, doc); err != nil {
+		return nil, fmt.Errorf("account file %s, error %v", file, err)
+	}
+
+	fields := doc.Data
+	if fields == nil {
+		if err := json.Unmarshal(raw, &fields); err != nil {
+			return nil, fmt.Errorf("account file %s, error %v", file, err)
+		}
+	}
+
+	_, hasVersion := fields["version"]
+
+	return &updateFile{account: acc, hasVersion: hasVersion, attributes: fields["attributes"]}, nil
+}
+
+// create creates an account, id is generated when missing
+func create(ctx context.Context, args []string, e *env) error {
+	fs, o := newFlagSet("create", e)
+	af := &accountFlags{}
+	af.register(fs)
+	fs.StringVar(&af.id, "id", "", "account id, generated when empty")
+	validate := fs.Bool("validate", false, "validate account before sending it")
+	key := fs.String("idempotency-key", "", "create idempotency key, generated when empty")
+	if _, err := parse(fs, args, 0); err != nil {
+		return err
+	}
+
+	acc, err := af.account(e)
+	if err != nil {
+		return err
+	}
+
+	d := acc.AccoundData
+	if d.Type == "" {
+		d.Type = "accounts"
+	}
+
+	if d.ID == "" {
+		d.ID = uuid.New().String()
+	}
+
+	if d.OrganisationID == "" {
+		return fmt.Errorf("organisation id is required, error %w", errUsage)
+	}
+
+	var opts []finn.Option
+	if *validate {
+		opts = append(opts, finn.WithValidation())
+	}
+
+	var copts []finn.CreateOption
+	if *key != "" {
+		copts = append(copts, finn.WithIdempotencyKey(*key))
+	}
+
+	return o.do(ctx, e, opts, func(ctx context.Context, api *finn.APIClient, p printer) error {
+		created, err := api.Create(ctx, acc, copts...)
+		if err != nil {
+			return err
+		}
+
+		return p(e.stdout, []*finn.AccoundData{created.AccoundData}, true)
+	})
+}
+
+// get fetches an account by id
+func get(ctx context.Context, args []string, e *env) error {
+	fs, o := newFlagSet("get", e)
+	pos, err := parse(fs, args, 1)
+	if err != nil {
+		return err
+	}
+
+	return o.do(ctx, e, nil, func(ctx context.Context, api *finn.APIClient, p printer) error {
+		acc, err := api.Fetch(ctx, pos[0])
+		if err != nil {
+			return err
+		}
+
+		return p(e.stdout, []*finn.AccoundData{acc.AccoundData}, true)
+	})
+}
+
+// list walks every account page matching filters
+func list(ctx context.Context, args []string, e *env) error {
+	fs, o := newFlagSet("list", e)
+	var filters stringList
+	fs.Var(&filters, "filter", "filter expression field=value[,value], repeatable, fields: "+strings.Join(filterNames(), ", "))
+	size := fs.Int("page-size", 100, "accounts per page")
+	limit := fs.Int("limit", 0, "max accounts to list, 0 lists all")
+	if _, err := parse(fs, args, 0); err != nil {
+		return err
+	}
+
+	filter, err := parseFilters(filters)
+	if err != nil {
+		return err
+	}
+
+	q := finn.NewListOptions(finn.NewPagination(0, *size), filter)
+
+	return o.do(ctx, e, nil, func(ctx context.Context, api *finn.APIClient, p printer) error {
+		var accounts []*finn.AccoundData
+		it := api.ListAll(ctx, q).WithPrefetch()
+		for it.Next() {
+			accounts = append(accounts, it.Account())
+			if *limit > 0 && len(accounts) >= *limit {
+				break
+			}
+		}
+
+		if err := it.Err(); err != nil {
+			return err
+		}
+
+		return p(e.stdout, accounts, false)
+	})
+}
+
+// remove deletes an account, current version is fetched when not provided
+func remove(ctx context.Context, args []string, e *env) error {
+	fs, o := newFlagSet("delete", e)
+	version := fs.Int("version", -1, "account version, current version is fetched when not set")
+	pos, err := parse(fs, args, 1)
+	if err != nil {
+		return err
+	}
+
+	return o.do(ctx, e, nil, func(ctx context.Context, api *finn.APIClient, _ printer) error {
+		v := *version
+		if v < 0 {
+			acc, err := api.Fetch(ctx, pos[0])
+			if err != nil {
+				return err
+			}
+			v = acc.AccoundData.Version
+		}
+
+		return api.Delete(ctx, pos[0], v)
+	})
+}
+
+// update patches account attributes, a file declaring a version, 0 included, is sent with that version
+// and flags applied over its attributes, otherwise current account is fetched and file attributes
+// and flags are applied over it, retrying on version conflicts
+func update(ctx context.Context, args []string, e *env) error {
+	fs, o := newFlagSet("update", e)
+	af := &accountFlags{}
+	af.register(fs)
+	pos, err := parse(fs, args, 1)
+	if err != nil {
+		return err
+	}
+
+	var fromFile *updateFile
+	if af.file != "" {
+		if fromFile, err = readUpdateFile(af.file, e.stdin); err != nil {
+			return err
+		}
+		d := fromFile.account.AccoundData
+		d.ID = pos[0]
+		if d.Type == "" {
+			d.Type = "accounts"
+		}
+
+		if d.Attributes == nil {
+			d.Attributes = &finn.Attributes{}
+		}
+		af.apply(fs, d.Attributes)
+	}
+
+	return o.do(ctx, e, nil, func(ctx context.Context, api *finn.APIClient, p printer) error {
+		var acc *finn.Account
+		var err error
+		if fromFile != nil && fromFile.hasVersion {
+			acc, err = api.Update(ctx, fromFile.account)
+		} else {
+			acc, err = api.FetchAndUpdate(ctx, pos[0], updateAttempts, func(acc *finn.Account) error {
+				if acc.AccoundData.Attributes == nil {
+					acc.AccoundData.Attributes = &finn.Attributes{}
+				}
+
+				if fromFile != nil && len(fromFile.attributes) > 0 {
+					if err := json.Unmarshal(fromFile.attributes, acc.AccoundData.Attributes); err != nil {
+						return fmt.Errorf("account file %s, error %v", af.file, err)
+					}
+				}
+				af.apply(fs, acc.AccoundData.Attributes)
+
+				return nil
+			})
+		}
+
+		if err != nil {
+			return err
+		}
+
+		return p(e.stdout, []*finn.AccoundData{acc.AccoundData}, true)
+	})
+}
+
+// newFlagSet creates a command flag set with shared flags
+func newFlagSet(name string, e *env) (*flag.FlagSet, *options) {
+	fs := flag.NewFlagSet("finnctl accounts "+name, flag.ContinueOnError)
+	fs.SetOutput(e.stderr)
+	o := &options{}
+	o.register(fs)
+
+	return fs, o
+}
+
+// parse parses flags interleaved with positional arguments, expecting exactly n positionals
+func parse(fs *flag.FlagSet, args []string, n int) ([]string, error) {
+	var pos []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			if err == flag.ErrHelp {
+				return nil, err
+			}
+
+			return nil, fmt.Errorf("%v, error %w", err, errUsage)
+		}
+
+		if fs.NArg() == 0 {
+			break
+		}
+		pos = append(pos, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+
+	if len(pos) != n {
+		return nil, fmt.Errorf("%s expects %d arguments got %d, error %w", fs.Name(), n, len(pos), errUsage)
+	}
+
+	return pos, nil
+}
+
+// do resolves configuration and runs fn bound to configured timeout
+func (o *options) do(ctx context.Context, e *env, opts []finn.Option, fn func(context.Context, *finn.APIClient, printer) error) error {
+	p, err := o.resolve(e)
+	if err != nil {
+		return err
+	}
+
+	timeout, err := p.timeout()
+	if err != nil {
+		return err
+	}
+
+	api, err := p.apiClient(opts...)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	return fn(ctx, api, printers[p.Output])
+}
+
+// parseFilters translates field=value[,value] expressions into a filter
+func parseFilters(exprs []string) (*finn.Filter, error) {
+	f := finn.NewFilter()
+	for _, expr := range exprs {
+		parts := strings.SplitN(expr, "=", 2)
+		if len(parts) != 2 || parts[1] == "" {
+			return nil, fmt.Errorf("invalid filter %s, expected field=value, error %w", expr, errUsage)
+		}
+
+		set, ok := filterFields[strings.TrimSpace(parts[0])]
+		if !ok {
+			return nil, fmt.Errorf("unknown filter field %s, error %w", parts[0], errUsage)
+		}
+		set(f, strings.Split(parts[1], ",")...)
+	}
+
+	return f, nil
+}
+
+// filterNames lists supported filter fields
+func filterNames() []string {
+	names := make([]string, 0, len(filterFields))
+	for n := range filterFields {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+
+	return names
+}
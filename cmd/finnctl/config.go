@@ -0,0 +1,180 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/marcosQuesada/finn"
+	client "github.com/marcosQuesada/finn/http"
+	"github.com/marcosQuesada/finn/oauth"
+)
+
+const defaultBaseURL = "http://localhost:8080/"
+const defaultTimeout = 30 * time.Second
+
+// profile defines api endpoint and credentials
+type profile struct {
+	BaseURL      string `json:"base_url"`
+	TokenURL     string `json:"token_url"`
+	ClientID     string `json:"client_id"`
+	ClientSecret string `json:"client_secret"`
+	Output       string `json:"output"`
+	Timeout      string `json:"timeout"`
+}
+
+// configFile holds named profiles, default profile is used when none is selected
+type configFile struct {
+	DefaultProfile string              `json:"default_profile"`
+	Profiles       map[string]*profile `json:"profiles"`
+}
+
+// options holds flags shared by every command
+type options struct {
+	config  string
+	profile string
+	flags   profile
+}
+
+// register declares shared flags
+func (o *options) register(fs *flag.FlagSet) {
+	fs.StringVar(&o.config, "config", "", "config file path (env FINN_CONFIG, default $HOME/.config/finnctl/config.json)")
+	fs.StringVar(&o.profile, "profile", "", "config file profile (env FINN_PROFILE)")
+	fs.StringVar(&o.flags.BaseURL, "base-url", "", "account api base url (env FINN_BASE_URL)")
+	fs.StringVar(&o.flags.TokenURL, "token-url", "", "oauth2 token endpoint (env FINN_TOKEN_URL)")
+	fs.StringVar(&o.flags.ClientID, "client-id", "", "oauth2 client id (env FINN_CLIENT_ID)")
+	fs.StringVar(&o.flags.ClientSecret, "client-secret", "", "oauth2 client secret (env FINN_CLIENT_SECRET)")
+	fs.StringVar(&o.flags.Output, "output", "", "output format: table, json, yaml or csv (env FINN_OUTPUT)")
+	fs.StringVar(&o.flags.Timeout, "timeout", "", "command timeout (env FINN_TIMEOUT, default 30s)")
+}
+
+// resolve merges flags, environment and config file profile, in that order of precedence
+func (o *options) resolve(e *env) (*profile, error) {
+	fromEnv := profile{
+		BaseURL:      e.getenv("FINN_BASE_URL"),
+		TokenURL:     e.getenv("FINN_TOKEN_URL"),
+		ClientID:     e.getenv("FINN_CLIENT_ID"),
+		ClientSecret: e.getenv("FINN_CLIENT_SECRET"),
+		Output:       e.getenv("FINN_OUTPUT"),
+		Timeout:      e.getenv("FINN_TIMEOUT"),
+	}
+
+	fromFile, err := o.load(e)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &profile{
+		BaseURL:      first(o.flags.BaseURL, fromEnv.BaseURL, fromFile.BaseURL, defaultBaseURL),
+		TokenURL:     first(o.flags.TokenURL, fromEnv.TokenURL, fromFile.TokenURL),
+		ClientID:     first(o.flags.ClientID, fromEnv.ClientID, fromFile.ClientID),
+		ClientSecret: first(o.flags.ClientSecret, fromEnv.ClientSecret, fromFile.ClientSecret),
+		Output:       first(o.flags.Output, fromEnv.Output, fromFile.Output, "table"),
+		Timeout:      first(o.flags.Timeout, fromEnv.Timeout, fromFile.Timeout),
+	}
+
+	if _, ok := printers[p.Output]; !ok {
+		return nil, fmt.Errorf("unknown output %s, error %w", p.Output, errUsage)
+	}
+
+	if p.ClientID != "" && p.TokenURL == "" {
+		return nil, fmt.Errorf("client id requires a token url, error %w", errUsage)
+	}
+
+	return p, nil
+}
+
+// load reads selected profile from config file, a missing default config file yields an empty profile
+func (o *options) load(e *env) (*profile, error) {
+	path := first(o.config, e.getenv("FINN_CONFIG"))
+	explicit := path != ""
+	if !explicit {
+		home := e.getenv("HOME")
+		if home == "" {
+			return &profile{}, nil
+		}
+		path = filepath.Join(home, ".config", "finnctl", "config.json")
+	}
+
+	f, err := os.Open(path)
+	if os.IsNotExist(err) && !explicit {
+		return &profile{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		_ = f.Close()
+	}()
+
+	cfg := &configFile{}
+	if err := json.NewDecoder(f).Decode(cfg); err != nil {
+		return nil, fmt.Errorf("config file %s, error %v", path, err)
+	}
+
+	name := first(o.profile, e.getenv("FINN_PROFILE"), cfg.DefaultProfile)
+	if name == "" {
+		return &profile{}, nil
+	}
+
+	p, ok := cfg.Profiles[name]
+	if !ok {
+		return nil, fmt.Errorf("profile %s not found on %s, error %w", name, path, errUsage)
+	}
+
+	return p, nil
+}
+
+// timeout parses profile timeout
+func (p *profile) timeout() (time.Duration, error) {
+	if p.Timeout == "" {
+		return defaultTimeout, nil
+	}
+
+	d, err := time.ParseDuration(p.Timeout)
+	if err != nil {
+		return 0, fmt.Errorf("invalid timeout %s, error %w", p.Timeout, errUsage)
+	}
+
+	return d, nil
+}
+
+// apiClient builds an api client from profile, requests are authorized when client credentials are set
+func (p *profile) apiClient(opts ...finn.Option) (*finn.APIClient, error) {
+	u, err := url.Parse(p.BaseURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid base url %s, error %w", p.BaseURL, errUsage)
+	}
+
+	copts := []client.Option{
+		client.WithUserAgent("finnctl"),
+		client.WithRetryPolicy(client.DefaultRetryPolicy()),
+	}
+
+	if p.ClientID != "" {
+		src := oauth.NewClientCredentials(oauth.Config{
+			TokenURL:     p.TokenURL,
+			ClientID:     p.ClientID,
+			ClientSecret: p.ClientSecret,
+		})
+		copts = append(copts, client.WithMiddleware(oauth.Middleware(src)))
+	}
+
+	return finn.NewAPIClient(client.NewClientWithUrl(u, copts...), opts...), nil
+}
+
+// first returns first non empty value
+func first(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,117 @@
+// Command finnctl manages account api accounts from the command line
+//
+//	finnctl accounts create|get|list|delete|update [flags] [id]
+//
+// base url, credentials and output format are taken from flags, FINN_* environment variables
+// or a config file profile, in that order
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/marcosQuesada/finn"
+	client "github.com/marcosQuesada/finn/http"
+	"github.com/marcosQuesada/finn/oauth"
+)
+
+// exit codes
+const (
+	exitOK           = 0
+	exitError        = 1
+	exitUsage        = 2
+	exitNotFound     = 3
+	exitConflict     = 4
+	exitUnauthorized = 5
+	exitInvalid      = 6
+)
+
+// errUsage happens on wrong command line arguments
+var errUsage = errors.New("usage error")
+
+const usage = `usage: finnctl accounts <command> [flags] [id]
+
+commands:
+  create    create an account from flags or a JSON file
+  get       fetch an account by id
+  list      list accounts, following all pages
+  delete    delete an account by id and version
+  update    update account attributes from flags or a JSON file
+
+run 'finnctl accounts <command> -h' to list command flags
+`
+
+// env holds process io and environment
+type env struct {
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+	getenv func(string) string
+}
+
+// command runs an accounts subcommand
+type command func(ctx context.Context, args []string, e *env) error
+
+var commands = map[string]command{
+	"create": create,
+	"get":    get,
+	"list":   list,
+	"delete": remove,
+	"update": update,
+}
+
+func main() {
+	e := &env{
+		stdin:  os.Stdin,
+		stdout: os.Stdout,
+		stderr: os.Stderr,
+		getenv: os.Getenv,
+	}
+
+	os.Exit(run(context.Background(), os.Args[1:], e))
+}
+
+// run executes command line returning process exit code
+func run(ctx context.Context, args []string, e *env) int {
+	if len(args) < 2 || args[0] != "accounts" {
+		_, _ = fmt.Fprint(e.stderr, usage)
+		return exitUsage
+	}
+
+	cmd, ok := commands[args[1]]
+	if !ok {
+		_, _ = fmt.Fprintf(e.stderr, "finnctl: unknown command %s\n\n%s", args[1], usage)
+		return exitUsage
+	}
+
+	err := cmd(ctx, args[2:], e)
+	if err == nil || errors.Is(err, flag.ErrHelp) {
+		return exitOK
+	}
+
+	_, _ = fmt.Fprintf(e.stderr, "finnctl: %v\n", err)
+
+	return exitCode(err)
+}
+
+// exitCode maps errors to process exit codes
+func exitCode(err error) int {
+	switch {
+	case errors.Is(err, errUsage):
+		return exitUsage
+	case errors.Is(err, client.ErrContentNotFound):
+		return exitNotFound
+	case errors.Is(err, finn.ErrVersionConflict), errors.Is(err, finn.ErrDuplicateAccount), errors.Is(err, client.ErrConflict):
+		return exitConflict
+	case errors.Is(err, client.ErrUnauthorized), errors.Is(err, client.ErrNotAuthorized), errors.Is(err, oauth.ErrTokenRequest):
+		return exitUnauthorized
+	case errors.Is(err, finn.ErrInvalidAccount), errors.Is(err, client.ErrBadRequest):
+		return exitInvalid
+	}
+
+	return exitError
+}
@@ -0,0 +1,352 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/marcosQuesada/finn"
+	"github.com/marcosQuesada/finn/finntest"
+)
+
+// result holds a finnctl execution outcome
+type result struct {
+	code   int
+	stdout string
+	stderr string
+}
+
+// execute runs finnctl against srv, environment values are taken from vars
+func execute(srv *finntest.Server, vars map[string]string, args ...string) *result {
+	stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+	e := &env{
+		stdin:  strings.NewReader(""),
+		stdout: stdout,
+		stderr: stderr,
+		getenv: func(k string) string {
+			if k == "FINN_BASE_URL" && srv != nil {
+				return srv.BaseURL().String()
+			}
+
+			return vars[k]
+		},
+	}
+
+	code := run(context.Background(), args, e)
+
+	return &result{code: code, stdout: stdout.String(), stderr: stderr.String()}
+}
+
+func TestAccounts_CreateGetDelete(t *testing.T) {
+	srv := finntest.NewServer()
+	defer srv.Close()
+
+	id := uuid.New().String()
+	res := execute(srv, nil, "accounts", "create", "--id", id, "--organisation-id", uuid.New().String(),
+		"--country", "GB", "--bank-id", "400300", "--bank-id-code", "GBDSC", "--bic", "NWBKGB22",
+		"--name", "Jane", "--name", "Doe", "--output", "json")
+	if res.code != exitOK {
+		t.Fatalf("unexpected exit code %d, stderr %s", res.code, res.stderr)
+	}
+
+	created := &finn.AccoundData{}
+	if err := json.Unmarshal([]byte(res.stdout), created); err != nil {
+		t.Fatalf("unexpected error decoding output %s, error %v", res.stdout, err)
+	}
+
+	if created.ID != id || strings.Join(created.Attributes.Name, " ") != "Jane Doe" {
+		t.Errorf("created account does not match, got %+v", created.Attributes)
+	}
+
+	res = execute(srv, nil, "accounts", "get", id)
+	if res.code != exitOK {
+		t.Fatalf("unexpected exit code %d, stderr %s", res.code, res.stderr)
+	}
+
+	if !strings.HasPrefix(res.stdout, "ID") || !strings.Contains(res.stdout, id) {
+		t.Errorf("unexpected table output, got %s", res.stdout)
+	}
+
+	if res = execute(srv, nil, "accounts", "delete", id); res.code != exitOK {
+		t.Fatalf("unexpected exit code %d, stderr %s", res.code, res.stderr)
+	}
+
+	if res = execute(srv, nil, "accounts", "get", id); res.code != exitNotFound {
+		t.Errorf("unexpected exit code, expected %d got %d", exitNotFound, res.code)
+	}
+}
+
+func TestAccounts_CreateFromFile(t *testing.T) {
+	srv := finntest.NewServer()
+	defer srv.Close()
+
+	dir, cleanup := tempDir(t)
+	defer cleanup()
+
+	file := filepath.Join(dir, "account.json")
+	doc := `{"data": {"organisation_id": "` + uuid.New().String() + `", "attributes": {"country": "ES", "bank_id": "fakeBankID"}}}`
+	if err := ioutil.WriteFile(file, []byte(doc), 0600); err != nil {
+		t.Fatalf("unexpected error writing account file, error %v", err)
+	}
+
+	res := execute(srv, nil, "accounts", "create", "--file", file, "--output", "yaml")
+	if res.code != exitOK {
+		t.Fatalf("unexpected exit code %d, stderr %s", res.code, res.stderr)
+	}
+
+	for _, want := range []string{"attributes:\n", `  bank_id: "fakeBankID"`, `  country: "ES"`, `type: "accounts"`, "version: 0"} {
+		if !strings.Contains(res.stdout, want) {
+			t.Errorf("yaml output does not contain %s, got %s", want, res.stdout)
+		}
+	}
+}
+
+func TestAccounts_ListFollowsPagesAndFilters(t *testing.T) {
+	srv := finntest.NewServer()
+	defer srv.Close()
+
+	org := uuid.New().String()
+	for i, country := range []string{"GB", "ES", "GB", "FR", "GB"} {
+		res := execute(srv, nil, "accounts", "create", "--organisation-id", org, "--country", country, "--bank-id", string(rune('a'+i)))
+		if res.code != exitOK {
+			t.Fatalf("unexpected exit code %d, stderr %s", res.code, res.stderr)
+		}
+	}
+
+	res := execute(srv, nil, "accounts", "list", "--page-size", "1", "--filter", "country=GB,FR", "--output", "csv")
+	if res.code != exitOK {
+		t.Fatalf("unexpected exit code %d, stderr %s", res.code, res.stderr)
+	}
+
+	rows, err := csv.NewReader(strings.NewReader(res.stdout)).ReadAll()
+	if err != nil {
+		t.Fatalf("unexpected error reading csv output, error %v", err)
+	}
+
+	if len(rows) != 5 || strings.Join(rows[0], ",") != strings.Join(columns, ",") {
+		t.Fatalf("unexpected csv output, got %v", rows)
+	}
+
+	for _, r := range rows[1:] {
+		if r[3] != "GB" && r[3] != "FR" {
+			t.Errorf("unexpected country on filtered list, got %s", r[3])
+		}
+	}
+
+	res = execute(srv, nil, "accounts", "list", "--page-size", "2", "--limit", "3", "--output", "json")
+	var accounts []*finn.AccoundData
+	if err := json.Unmarshal([]byte(res.stdout), &accounts); err != nil {
+		t.Fatalf("unexpected error decoding output %s, error %v", res.stdout, err)
+	}
+
+	if len(accounts) != 3 {
+		t.Errorf("limit not applied, expected 3 got %d", len(accounts))
+	}
+}
+
+func TestAccounts_Update(t *testing.T) {
+	srv := finntest.NewServer()
+	defer srv.Close()
+
+	id := uuid.New().String()
+	res := execute(srv, nil, "accounts", "create", "--id", id, "--organisation-id", uuid.New().String(), "--country", "GB", "--bank-id", "400300")
+	if res.code != exitOK {
+		t.Fatalf("unexpected exit code %d, stderr %s", res.code, res.stderr)
+	}
+
+	res = execute(srv, nil, "accounts", "update", id, "--status", "closed", "--output", "json")
+	if res.code != exitOK {
+		t.Fatalf("unexpected exit code %d, stderr %s", res.code, res.stderr)
+	}
+
+	updated := &finn.AccoundData{}
+	if err := json.Unmarshal([]byte(res.stdout), updated); err != nil {
+		t.Fatalf("unexpected error decoding output %s, error %v", res.stdout, err)
+	}
+
+	if updated.Attributes.Status != "closed" || updated.Attributes.BankID != "400300" || updated.Version != 1 {
+		t.Errorf("updated account does not match, got version %d %+v", updated.Version, updated.Attributes)
+	}
+
+	if res = execute(srv, nil, "accounts", "delete", id, "--version", "0"); res.code != exitConflict {
+		t.Errorf("unexpected exit code on stale version, expected %d got %d", exitConflict, res.code)
+	}
+}
+
+func TestAccounts_UpdateFromFileDeclaringVersionZero(t *testing.T) {
+	srv := finntest.NewServer()
+	defer srv.Close()
+
+	id := uuid.New().String()
+	res := execute(srv, nil, "accounts", "create", "--id", id, "--organisation-id", uuid.New().String(), "--country", "GB", "--bank-id", "400300")
+	if res.code != exitOK {
+		t.Fatalf("unexpected exit code %d, stderr %s", res.code, res.stderr)
+	}
+
+	f, err := ioutil.TempFile("", "finnctl")
+	if err != nil {
+		t.Fatalf("unexpected error creating temp file, error %v", err)
+	}
+	defer func() {
+		_ = os.Remove(f.Name())
+	}()
+
+	if _, err := f.WriteString(`{"data": {"version": 0, "attributes": {"status": "closed"}}}`); err != nil {
+		t.Fatalf("unexpected error writing account file, error %v", err)
+	}
+	_ = f.Close()
+
+	res = execute(srv, nil, "accounts", "update", id, "--file", f.Name(), "--output", "json")
+	if res.code != exitOK {
+		t.Fatalf("unexpected exit code %d, stderr %s", res.code, res.stderr)
+	}
+
+	updated := &finn.AccoundData{}
+	if err := json.Unmarshal([]byte(res.stdout), updated); err != nil {
+		t.Fatalf("unexpected error decoding output %s, error %v", res.stdout, err)
+	}
+
+	if updated.Attributes.Status != "closed" || updated.Attributes.BankID != "400300" || updated.Version != 1 {
+		t.Errorf("updated account does not match, got version %d %+v", updated.Version, updated.Attributes)
+	}
+
+	if res = execute(srv, nil, "accounts", "update", id, "--file", f.Name()); res.code != exitConflict {
+		t.Errorf("version 0 file not sent as is, expected exit code %d got %d", exitConflict, res.code)
+	}
+}
+
+func TestAccounts_UpdateFromFileWithVersionAppliesFlags(t *testing.T) {
+	srv := finntest.NewServer()
+	defer srv.Close()
+
+	id := uuid.New().String()
+	res := execute(srv, nil, "accounts", "create", "--id", id, "--organisation-id", uuid.New().String(), "--country", "GB", "--bank-id", "400300")
+	if res.code != exitOK {
+		t.Fatalf("unexpected exit code %d, stderr %s", res.code, res.stderr)
+	}
+
+	var mu sync.Mutex
+	var body []byte
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPatch {
+			raw, _ := ioutil.ReadAll(r.Body)
+			r.Body = ioutil.NopCloser(bytes.NewReader(raw))
+			mu.Lock()
+			body = raw
+			mu.Unlock()
+		}
+		srv.ServeHTTP(w, r)
+	}))
+	defer proxy.Close()
+
+	dir, cleanup := tempDir(t)
+	defer cleanup()
+
+	file := filepath.Join(dir, "account.json")
+	if err := ioutil.WriteFile(file, []byte(`{"data": {"version": 0, "attributes": {"bank_id": "400301"}}}`), 0600); err != nil {
+		t.Fatalf("unexpected error writing account file, error %v", err)
+	}
+
+	vars := map[string]string{"FINN_BASE_URL": proxy.URL + "/"}
+	res = execute(nil, vars, "accounts", "update", id, "--file", file, "--status", "closed", "--output", "json")
+	if res.code != exitOK {
+		t.Fatalf("unexpected exit code %d, stderr %s", res.code, res.stderr)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	doc := &struct {
+		Data struct {
+			Version    int                        `json:"version"`
+			Attributes map[string]json.RawMessage `json:"attributes"`
+		} `json:"data"`
+	}{}
+	if err := json.Unmarshal(body, doc); err != nil {
+		t.Fatalf("unexpected error decoding request body %s, error %v", body, err)
+	}
+
+	attr := doc.Data.Attributes
+	if string(attr["status"]) != `"closed"` || string(attr["bank_id"]) != `"400301"` || doc.Data.Version != 0 {
+		t.Errorf("request body does not hold file attributes and flags, got %s", body)
+	}
+}
+
+func TestAccounts_ConfigProfiles(t *testing.T) {
+	srv := finntest.NewServer()
+	defer srv.Close()
+
+	dir, cleanup := tempDir(t)
+	defer cleanup()
+
+	config := filepath.Join(dir, "config.json")
+	doc := `{"default_profile": "broken", "profiles": {
+		"broken": {"base_url": "http://127.0.0.1:1/"},
+		"local": {"base_url": "` + srv.BaseURL().String() + `", "output": "json"}
+	}}`
+	if err := ioutil.WriteFile(config, []byte(doc), 0600); err != nil {
+		t.Fatalf("unexpected error writing config file, error %v", err)
+	}
+
+	vars := map[string]string{"FINN_CONFIG": config, "FINN_TIMEOUT": "2s"}
+	if res := execute(nil, vars, "accounts", "list", "--timeout", "200ms"); res.code != exitError {
+		t.Errorf("unexpected exit code on default profile, expected %d got %d", exitError, res.code)
+	}
+
+	res := execute(nil, vars, "accounts", "list", "--profile", "local")
+	if res.code != exitOK || strings.TrimSpace(res.stdout) != "[]" {
+		t.Errorf("unexpected result on selected profile, code %d stdout %s stderr %s", res.code, res.stdout, res.stderr)
+	}
+
+	vars["FINN_PROFILE"] = "missing"
+	if res := execute(nil, vars, "accounts", "list"); res.code != exitUsage {
+		t.Errorf("unexpected exit code on missing profile, expected %d got %d", exitUsage, res.code)
+	}
+}
+
+func TestRun_ExitCodes(t *testing.T) {
+	srv := finntest.NewServer()
+	defer srv.Close()
+
+	tests := []struct {
+		args []string
+		code int
+	}{
+		{args: []string{}, code: exitUsage},
+		{args: []string{"accounts", "rename"}, code: exitUsage},
+		{args: []string{"accounts", "get"}, code: exitUsage},
+		{args: []string{"accounts", "get", "id", "--unknown"}, code: exitUsage},
+		{args: []string{"accounts", "list", "--filter", "colour=red"}, code: exitUsage},
+		{args: []string{"accounts", "list", "--output", "xml"}, code: exitUsage},
+		{args: []string{"accounts", "create", "--country", "GB"}, code: exitUsage},
+		{args: []string{"accounts", "create", "--organisation-id", uuid.New().String(), "--country", "GB", "--validate"}, code: exitInvalid},
+		{args: []string{"accounts", "get", uuid.New().String()}, code: exitNotFound},
+		{args: []string{"accounts", "get", "-h"}, code: exitOK},
+	}
+
+	for _, test := range tests {
+		if res := execute(srv, nil, test.args...); res.code != test.code {
+			t.Errorf("unexpected exit code on %v, expected %d got %d, stderr %s", test.args, test.code, res.code, res.stderr)
+		}
+	}
+}
+
+func tempDir(t *testing.T) (string, func()) {
+	dir, err := ioutil.TempDir("", "finnctl")
+	if err != nil {
+		t.Fatalf("unexpected error creating temp dir, error %v", err)
+	}
+
+	return dir, func() {
+		_ = os.RemoveAll(dir)
+	}
+}
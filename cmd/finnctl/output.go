@@ -0,0 +1,184 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/marcosQuesada/finn"
+)
+
+// printer writes accounts, single flags a one account result, printed as an object instead of a list
+type printer func(w io.Writer, accounts []*finn.AccoundData, single bool) error
+
+var printers = map[string]printer{
+	"table": printTable,
+	"json":  printJSON,
+	"yaml":  printYAML,
+	"csv":   printCSV,
+}
+
+// columns are printed on table and csv outputs
+var columns = []string{"id", "organisation_id", "version", "country", "bank_id", "bank_id_code", "bic", "account_number", "iban", "status"}
+
+// row returns account column values
+func row(a *finn.AccoundData) []string {
+	attr := a.Attributes
+	if attr == nil {
+		attr = &finn.Attributes{}
+	}
+
+	return []string{a.ID, a.OrganisationID, strconv.Itoa(a.Version), attr.Country, attr.BankID, attr.BankIDCode,
+		attr.Bic, attr.AccountNumber, attr.Iban, attr.Status}
+}
+
+// printTable writes aligned columns
+func printTable(w io.Writer, accounts []*finn.AccoundData, _ bool) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, strings.ToUpper(strings.Join(columns, "\t")))
+	for _, a := range accounts {
+		_, _ = fmt.Fprintln(tw, strings.Join(row(a), "\t"))
+	}
+
+	return tw.Flush()
+}
+
+// printCSV writes columns as csv with header
+func printCSV(w io.Writer, accounts []*finn.AccoundData, _ bool) error {
+	cw := csv.NewWriter(w)
+	_ = cw.Write(columns)
+	for _, a := range accounts {
+		_ = cw.Write(row(a))
+	}
+	cw.Flush()
+
+	return cw.Error()
+}
+
+// printJSON writes indented account data
+func printJSON(w io.Writer, accounts []*finn.AccoundData, single bool) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+
+	return enc.Encode(document(accounts, single))
+}
+
+// printYAML writes account data as yaml, documents are translated from their json encoding
+// so field names match json ones
+func printYAML(w io.Writer, accounts []*finn.AccoundData, single bool) error {
+	b, err := json.Marshal(document(accounts, single))
+	if err != nil {
+		return err
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return err
+	}
+
+	for _, l := range yamlLines(v, 0) {
+		if _, err := fmt.Fprintln(w, l); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// document selects printed value, a single account or the account list
+func document(accounts []*finn.AccoundData, single bool) interface{} {
+	if single && len(accounts) == 1 {
+		return accounts[0]
+	}
+
+	if accounts == nil {
+		return []*finn.AccoundData{}
+	}
+
+	return accounts
+}
+
+// yamlLines renders a decoded json value as block style yaml lines
+func yamlLines(v interface{}, indent int) []string {
+	pad := strings.Repeat(" ", indent)
+	switch t := v.(type) {
+	case map[string]interface{}:
+		if len(t) == 0 {
+			return []string{pad + "{}"}
+		}
+
+		keys := make([]string, 0, len(t))
+		for k := range t {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		var lines []string
+		for _, k := range keys {
+			if nested(t[k]) {
+				lines = append(lines, pad+k+":")
+				lines = append(lines, yamlLines(t[k], indent+2)...)
+				continue
+			}
+			lines = append(lines, pad+k+": "+yamlScalar(t[k]))
+		}
+
+		return lines
+	case []interface{}:
+		if len(t) == 0 {
+			return []string{pad + "[]"}
+		}
+
+		var lines []string
+		for _, item := range t {
+			if !nested(item) {
+				lines = append(lines, pad+"- "+yamlScalar(item))
+				continue
+			}
+
+			item := yamlLines(item, indent+2)
+			item[0] = pad + "- " + strings.TrimLeft(item[0], " ")
+			lines = append(lines, item...)
+		}
+
+		return lines
+	}
+
+	return []string{pad + yamlScalar(v)}
+}
+
+// nested checks if value is a non empty map or list
+func nested(v interface{}) bool {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		return len(t) > 0
+	case []interface{}:
+		return len(t) > 0
+	}
+
+	return false
+}
+
+// yamlScalar renders scalars, strings are double quoted so they are never taken as other types
+func yamlScalar(v interface{}) string {
+	switch t := v.(type) {
+	case nil:
+		return "null"
+	case string:
+		return strconv.Quote(t)
+	case map[string]interface{}:
+		return "{}"
+	case []interface{}:
+		return "[]"
+	}
+
+	return fmt.Sprint(v)
+}
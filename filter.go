@@ -0,0 +1,121 @@
+package finn
+
+import (
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// Query builds account list query string, as Pagination, Filter or ListOptions
+type Query interface {
+	QueryString() string
+}
+
+// Filter defines server side account list filters, multiple values on the same field are ORed,
+// zero value is an empty filter ready to use
+type Filter struct {
+	values map[string][]string
+}
+
+// NewFilter instantiates an empty filter
+func NewFilter() *Filter {
+	return &Filter{
+		values: make(map[string][]string),
+	}
+}
+
+// BankID filters accounts by bank id
+func (f *Filter) BankID(v ...string) *Filter {
+	return f.add("bank_id", v)
+}
+
+// BankIDCode filters accounts by bank id code
+func (f *Filter) BankIDCode(v ...string) *Filter {
+	return f.add("bank_id_code", v)
+}
+
+// AccountNumber filters accounts by account number
+func (f *Filter) AccountNumber(v ...string) *Filter {
+	return f.add("account_number", v)
+}
+
+// Iban filters accounts by IBAN
+func (f *Filter) Iban(v ...string) *Filter {
+	return f.add("iban", v)
+}
+
+// Country filters accounts by country
+func (f *Filter) Country(v ...string) *Filter {
+	return f.add("country", v)
+}
+
+// CustomerID filters accounts by customer id
+func (f *Filter) CustomerID(v ...string) *Filter {
+	return f.add("customer_id", v)
+}
+
+// QueryString translates filter to query string, fields are sorted and values url encoded
+func (f *Filter) QueryString() string {
+	keys := make([]string, 0, len(f.values))
+	for k := range f.values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	parts := make([]string, 0, len(keys))
+	for _, k := range keys {
+		values := make([]string, len(f.values[k]))
+		for i, v := range f.values[k] {
+			values[i] = url.QueryEscape(v)
+		}
+		parts = append(parts, "filter["+k+"]="+strings.Join(values, ","))
+	}
+
+	return strings.Join(parts, "&")
+}
+
+// add appends non empty values to filter field
+func (f *Filter) add(field string, values []string) *Filter {
+	for _, v := range values {
+		if v == "" {
+			continue
+		}
+
+		if f.values == nil {
+			f.values = make(map[string][]string)
+		}
+		f.values[field] = append(f.values[field], v)
+	}
+
+	return f
+}
+
+// ListOptions combines pagination and filters on account listing
+type ListOptions struct {
+	Pagination *Pagination
+	Filter     *Filter
+}
+
+// NewListOptions instantiates list options, both pagination and filter are optional
+func NewListOptions(pags *Pagination, filter *Filter) *ListOptions {
+	return &ListOptions{
+		Pagination: pags,
+		Filter:     filter,
+	}
+}
+
+// QueryString joins pagination and filter query strings
+func (o *ListOptions) QueryString() string {
+	var parts []string
+	if o.Pagination != nil {
+		parts = append(parts, o.Pagination.QueryString())
+	}
+
+	if o.Filter != nil {
+		if q := o.Filter.QueryString(); q != "" {
+			parts = append(parts, q)
+		}
+	}
+
+	return strings.Join(parts, "&")
+}
@@ -0,0 +1,96 @@
+package finn
+
+import (
+	"context"
+	"testing"
+)
+
+func TestFilterQueryStringSortsFieldsAndEncodesValues(t *testing.T) {
+	f := NewFilter().
+		Country("GB", "FR").
+		BankID("400300").
+		Iban("GB11 NWBK 4003 0041 4268 19").
+		CustomerID("a&b=c").
+		BankIDCode("").
+		AccountNumber("41426819")
+
+	want := "filter[account_number]=41426819&filter[bank_id]=400300&filter[country]=GB,FR" +
+		"&filter[customer_id]=a%26b%3Dc&filter[iban]=GB11+NWBK+4003+0041+4268+19"
+	if got := f.QueryString(); got != want {
+		t.Errorf("filter query string does not match, expected %s got %s", want, got)
+	}
+}
+
+func TestFilterZeroValueIsUsable(t *testing.T) {
+	var f Filter
+	if got := f.QueryString(); got != "" {
+		t.Errorf("unexpected empty filter query string, got %s", got)
+	}
+
+	f.Country("GB")
+	(&Filter{}).BankID("400300")
+
+	if got, want := f.QueryString(), "filter[country]=GB"; got != want {
+		t.Errorf("filter query string does not match, expected %s got %s", want, got)
+	}
+}
+
+func TestListOptionsQueryStringCombinesPaginationAndFilters(t *testing.T) {
+	tests := []struct {
+		opts  *ListOptions
+		query string
+	}{
+		{
+			opts:  NewListOptions(NewPagination(1, 10), NewFilter().BankIDCode("GBDSC")),
+			query: "page[number]=1&page[size]=10&filter[bank_id_code]=GBDSC",
+		},
+		{
+			opts:  NewListOptions(nil, NewFilter().Country("ES")),
+			query: "filter[country]=ES",
+		},
+		{
+			opts:  NewListOptions(NewPagination(0, 5), NewFilter()),
+			query: "page[number]=0&page[size]=5",
+		},
+		{
+			opts:  NewListOptions(nil, nil),
+			query: "",
+		},
+	}
+
+	for _, test := range tests {
+		if got := test.opts.QueryString(); got != test.query {
+			t.Errorf("list options query string does not match, expected %s got %s", test.query, got)
+		}
+	}
+}
+
+func TestListAccountsWithOptionsSendsFiltersOnRequestURI(t *testing.T) {
+	h := &fakeHTTPClient{}
+	api := NewAPIClient(h)
+
+	opts := NewListOptions(NewPagination(0, 10), NewFilter().AccountNumber("41426819"))
+	_, err := api.List(context.Background(), opts)
+	if err != nil {
+		t.Fatalf("unexpected error listing accounts, error %v", err)
+	}
+
+	want := "v1/organisation/accounts?page[number]=0&page[size]=10&filter[account_number]=41426819"
+	if h.uri != want {
+		t.Errorf("request uri does not match, expected %s got %s", want, h.uri)
+	}
+}
+
+func TestListAccountsWithoutQueryOmitsQueryString(t *testing.T) {
+	h := &fakeHTTPClient{}
+	api := NewAPIClient(h)
+
+	_, err := api.List(context.Background(), NewListOptions(nil, nil))
+	if err != nil {
+		t.Fatalf("unexpected error listing accounts, error %v", err)
+	}
+
+	if got, want := h.uri, "v1/organisation/accounts"; got != want {
+		t.Errorf("request uri does not match, expected %s got %s", want, got)
+	}
+}
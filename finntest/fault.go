@@ -0,0 +1,327 @@
+package finntest
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RouteAccounts matches account collection route, as create and list
+const RouteAccounts = accountsPath
+
+// RouteAccount matches single account route, as fetch, update and delete
+const RouteAccount = accountsPath + "/{id}"
+
+// Latency defines a request delay distribution
+type Latency interface {
+	Delay(r *rand.Rand) time.Duration
+}
+
+// FixedLatency delays every request by the same duration
+type FixedLatency time.Duration
+
+// Delay returns fixed delay
+func (l FixedLatency) Delay(_ *rand.Rand) time.Duration {
+	return time.Duration(l)
+}
+
+// UniformLatency delays requests by a uniformly distributed duration in [Min, Max)
+type UniformLatency struct {
+	Min time.Duration
+	Max time.Duration
+}
+
+// Delay returns a uniformly distributed delay
+func (l UniformLatency) Delay(r *rand.Rand) time.Duration {
+	if l.Max <= l.Min {
+		return l.Min
+	}
+
+	return l.Min + time.Duration(r.Int63n(int64(l.Max-l.Min)))
+}
+
+// NormalLatency delays requests by a normally distributed duration, negative values are clamped to zero
+type NormalLatency struct {
+	Mean   time.Duration
+	StdDev time.Duration
+}
+
+// Delay returns a normally distributed delay
+func (l NormalLatency) Delay(r *rand.Rand) time.Duration {
+	d := time.Duration(r.NormFloat64()*float64(l.StdDev)) + l.Mean
+	if d < 0 {
+		return 0
+	}
+
+	return d
+}
+
+// Fault defines a misbehaviour applied on matching requests, latency is combined with the first
+// matching fault action, in declaration order: Drop, StatusCode, Duplicate, Truncate, Malformed,
+// StaleVersion and DropResponse
+type Fault struct {
+	// Method matches request method, empty matches any
+	Method string
+	// Route matches RouteAccounts or RouteAccount, empty matches any
+	Route string
+	// From and To select fault matching request counts, starting on 1, zero values are unbounded
+	From int
+	To   int
+	// Rate applies fault with probability in (0, 1], zero applies it always
+	Rate float64
+
+	// Latency delays request handling
+	Latency Latency
+	// Drop closes connection before handling request
+	Drop bool
+	// StatusCode replies an error status code without handling request
+	StatusCode int
+	// RetryAfter sets Retry-After header on StatusCode replies
+	RetryAfter time.Duration
+	// Duplicate replies previous response on the same method and request path
+	Duplicate bool
+	// Truncate replies half of the response body and closes connection
+	Truncate bool
+	// Malformed replies a non parseable json body
+	Malformed bool
+	// StaleVersion replies account data with a decremented version
+	StaleVersion bool
+	// DropResponse handles request and closes connection without response
+	DropResponse bool
+}
+
+// FaultPlan scripts faults per route and request count, random decisions are seeded
+// so the same plan misbehaves in the same way on each run
+type FaultPlan struct {
+	mutex  sync.Mutex
+	rand   *rand.Rand
+	faults []*Fault
+	counts []int
+	last   map[string]*recorded
+}
+
+// recorded holds a captured response
+type recorded struct {
+	status int
+	header http.Header
+	body   []byte
+}
+
+// NewFaultPlan instantiates a fault plan
+func NewFaultPlan(seed int64, faults ...*Fault) *FaultPlan {
+	return &FaultPlan{
+		rand:   rand.New(rand.NewSource(seed)),
+		faults: faults,
+		counts: make([]int, len(faults)),
+		last:   make(map[string]*recorded),
+	}
+}
+
+// Inject applies fault plan on server requests, nil plan restores normal behaviour
+func (s *Server) Inject(p *FaultPlan) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.faults = p
+}
+
+// serve handles request applying matching faults
+func (p *FaultPlan) serve(w http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	route := routeOf(r)
+	delay, f := p.match(r.Method, route)
+
+	if delay > 0 {
+		t := time.NewTimer(delay)
+		select {
+		case <-r.Context().Done():
+			t.Stop()
+			return
+		case <-t.C:
+		}
+	}
+
+	key := r.Method + " " + r.URL.Path
+	switch {
+	case f == nil:
+	case f.Drop:
+		drop(w, nil)
+		return
+	case f.StatusCode != 0:
+		if f.RetryAfter > 0 {
+			w.Header().Set("Retry-After", strconv.Itoa(int(f.RetryAfter/time.Second)))
+		}
+		writeError(w, f.StatusCode, "injected fault")
+		return
+	case f.Duplicate:
+		if rec := p.previous(key); rec != nil {
+			rec.write(w)
+			return
+		}
+	}
+
+	rw := httptest.NewRecorder()
+	next(rw, r)
+	rec := &recorded{status: rw.Code, header: rw.Header(), body: rw.Body.Bytes()}
+	p.record(key, rec)
+
+	switch {
+	case f == nil:
+	case f.Truncate:
+		drop(w, rec)
+		return
+	case f.Malformed:
+		rec = &recorded{status: rec.status, header: rec.header, body: []byte(`{"data": {"id": }`)}
+	case f.StaleVersion:
+		rec = &recorded{status: rec.status, header: rec.header, body: staleVersion(rec.body)}
+	case f.DropResponse:
+		drop(w, nil)
+		return
+	}
+
+	rec.write(w)
+}
+
+// match counts request on matching faults, returns total latency and first fault with an action
+func (p *FaultPlan) match(method, route string) (time.Duration, *Fault) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	var delay time.Duration
+	var action *Fault
+	for i, f := range p.faults {
+		if (f.Method != "" && f.Method != method) || (f.Route != "" && f.Route != route) {
+			continue
+		}
+
+		p.counts[i]++
+		n := p.counts[i]
+		if n < f.From || (f.To > 0 && n > f.To) {
+			continue
+		}
+
+		if f.Rate > 0 && p.rand.Float64() >= f.Rate {
+			continue
+		}
+
+		if f.Latency != nil {
+			delay += f.Latency.Delay(p.rand)
+		}
+
+		if action == nil && f.hasAction() {
+			action = f
+		}
+	}
+
+	return delay, action
+}
+
+// hasAction checks if fault alters response
+func (f *Fault) hasAction() bool {
+	return f.Drop || f.StatusCode != 0 || f.Duplicate || f.Truncate || f.Malformed || f.StaleVersion || f.DropResponse
+}
+
+// previous returns last recorded response by key
+func (p *FaultPlan) previous(key string) *recorded {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	return p.last[key]
+}
+
+// record stores last response by key
+func (p *FaultPlan) record(key string, rec *recorded) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.last[key] = rec
+}
+
+// write replies recorded response
+func (rec *recorded) write(w http.ResponseWriter) {
+	for k, v := range rec.header {
+		w.Header()[k] = v
+	}
+	w.Header().Set("Content-Length", strconv.Itoa(len(rec.body)))
+	w.WriteHeader(rec.status)
+	_, _ = w.Write(rec.body)
+}
+
+// drop hijacks connection and closes it, when rec is provided half of its body is written first
+func drop(w http.ResponseWriter, rec *recorded) {
+	hj, ok := w.(http.Hijacker)
+	if !ok {
+		return
+	}
+
+	conn, buf, err := hj.Hijack()
+	if err != nil {
+		return
+	}
+	defer func() {
+		_ = conn.Close()
+	}()
+
+	if rec != nil {
+		writeTruncated(buf, rec)
+	}
+}
+
+// writeTruncated writes raw response declaring full content length with half of the body
+func writeTruncated(buf *bufio.ReadWriter, rec *recorded) {
+	_, _ = fmt.Fprintf(buf, "HTTP/1.1 %d %s\r\n", rec.status, http.StatusText(rec.status))
+	_, _ = fmt.Fprintf(buf, "Content-Type: %s\r\n", jsonContentType)
+	_, _ = fmt.Fprintf(buf, "Content-Length: %d\r\n\r\n", len(rec.body))
+	_, _ = buf.Write(rec.body[:len(rec.body)/2])
+	_ = buf.Flush()
+}
+
+// staleVersion decrements account data version, non account bodies are kept as they are
+func staleVersion(body []byte) []byte {
+	doc := make(map[string]json.RawMessage)
+	if err := json.Unmarshal(body, &doc); err != nil {
+		return body
+	}
+
+	data := make(map[string]interface{})
+	if err := json.Unmarshal(doc["data"], &data); err != nil {
+		return body
+	}
+
+	if v, ok := data["version"].(float64); ok && v > 0 {
+		data["version"] = v - 1
+	}
+
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return body
+	}
+	doc["data"] = raw
+
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(doc); err != nil {
+		return body
+	}
+
+	return buf.Bytes()
+}
+
+// routeOf translates request path to route
+func routeOf(r *http.Request) string {
+	if r.URL.Path == accountsPath {
+		return RouteAccounts
+	}
+
+	if strings.HasPrefix(r.URL.Path, accountsPath+"/") {
+		return RouteAccount
+	}
+
+	return r.URL.Path
+}
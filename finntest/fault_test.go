@@ -0,0 +1,269 @@
+package finntest
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	nethttp "net/http"
+	"testing"
+	"time"
+
+	"github.com/marcosQuesada/finn"
+	"github.com/marcosQuesada/finn/http"
+)
+
+func TestFaultPlan_StatusCodeStormIsRecoveredByRetries(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+
+	acc := newAccount("GB")
+	if _, err := newAPIClient(srv).Create(context.Background(), acc); err != nil {
+		t.Fatalf("unexpected error creating account, error %v", err)
+	}
+
+	srv.Inject(NewFaultPlan(1, &Fault{
+		Method:     nethttp.MethodGet,
+		Route:      RouteAccount,
+		To:         2,
+		StatusCode: nethttp.StatusServiceUnavailable,
+	}))
+
+	p := http.DefaultRetryPolicy()
+	p.BaseBackoff = time.Millisecond
+	api := finn.NewAPIClient(http.NewClientWithRetryPolicy(srv.BaseURL(), p))
+
+	if _, err := api.Fetch(context.Background(), acc.AccoundData.ID); err != nil {
+		t.Fatalf("unexpected error fetching account, error %v", err)
+	}
+
+	_, err := newAPIClient(srv).Fetch(context.Background(), acc.AccoundData.ID)
+	if err != nil {
+		t.Errorf("unexpected error once storm is over, error %v", err)
+	}
+}
+
+func TestFaultPlan_StatusCodeStormSetsRetryAfter(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+
+	srv.Inject(NewFaultPlan(1, &Fault{StatusCode: nethttp.StatusTooManyRequests, RetryAfter: 3 * time.Second}))
+
+	_, err := newAPIClient(srv).List(context.Background(), finn.NewPagination(0, 10))
+	var apiErr *http.APIError
+	if !errors.As(err, &apiErr) || !errors.Is(err, http.ErrTooManyRequests) {
+		t.Fatalf("unexpected error type, expected too many requests got %v", err)
+	}
+
+	if got, want := apiErr.Header.Get("Retry-After"), "3"; got != want {
+		t.Errorf("retry after does not match, expected %s got %s", want, got)
+	}
+}
+
+func TestFaultPlan_RateIsDeterministicBySeed(t *testing.T) {
+	failures := func() int {
+		srv := NewServer()
+		defer srv.Close()
+
+		srv.Inject(NewFaultPlan(42, &Fault{Rate: 0.5, StatusCode: nethttp.StatusInternalServerError}))
+		api := newAPIClient(srv)
+
+		n := 0
+		for i := 0; i < 20; i++ {
+			if _, err := api.List(context.Background(), finn.NewPagination(0, 10)); err != nil {
+				n++
+			}
+		}
+
+		return n
+	}
+
+	first := failures()
+	if first == 0 || first == 20 {
+		t.Fatalf("unexpected failures, got %d", first)
+	}
+
+	if second := failures(); second != first {
+		t.Errorf("failures are not deterministic, expected %d got %d", first, second)
+	}
+}
+
+func TestFaultPlan_LatencyTriggersClientTimeouts(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+
+	srv.Inject(NewFaultPlan(1, &Fault{Latency: FixedLatency(time.Second)}))
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*50)
+	defer cancel()
+
+	_, err := newAPIClient(srv).List(ctx, finn.NewPagination(0, 10))
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("unexpected error type, expected deadline exceeded got %v", err)
+	}
+}
+
+func TestFaultPlan_DroppedAndTruncatedResponsesFailOnClient(t *testing.T) {
+	tests := []*Fault{
+		{Drop: true},
+		{DropResponse: true},
+		{Truncate: true},
+		{Malformed: true},
+	}
+
+	for _, f := range tests {
+		srv := NewServer()
+		srv.Inject(NewFaultPlan(1, f))
+
+		_, err := newAPIClient(srv).Create(context.Background(), newAccount("GB"))
+		if err == nil {
+			t.Errorf("expected error on fault %+v", f)
+		}
+
+		srv.Close()
+	}
+}
+
+func TestFaultPlan_DropResponseHandlesRequest(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+
+	srv.Inject(NewFaultPlan(1, &Fault{Method: nethttp.MethodPost, DropResponse: true}))
+
+	_, err := newAPIClient(srv).Create(context.Background(), newAccount("GB"))
+	var reqErr *http.RequestError
+	if !errors.As(err, &reqErr) {
+		t.Fatalf("unexpected error type, expected request error got %v", err)
+	}
+
+	if got, want := srv.Len(), 1; got != want {
+		t.Errorf("unexpected stored accounts, expected %d got %d", want, got)
+	}
+}
+
+func TestFaultPlan_StaleVersionIsRecoveredByFetchAndUpdate(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	api := newAPIClient(srv)
+
+	acc := newAccount("GB")
+	if _, err := api.Create(context.Background(), acc); err != nil {
+		t.Fatalf("unexpected error creating account, error %v", err)
+	}
+
+	setStatus := func(status string) func(*finn.Account) error {
+		return func(a *finn.Account) error {
+			a.AccoundData.Attributes = &finn.Attributes{Status: status}
+			return nil
+		}
+	}
+
+	if _, err := api.FetchAndUpdate(context.Background(), acc.AccoundData.ID, 1, setStatus("pending")); err != nil {
+		t.Fatalf("unexpected error updating account, error %v", err)
+	}
+
+	srv.Inject(NewFaultPlan(1, &Fault{Method: nethttp.MethodGet, Route: RouteAccount, To: 1, StaleVersion: true}))
+
+	updated, err := api.FetchAndUpdate(context.Background(), acc.AccoundData.ID, 2, setStatus("confirmed"))
+	if err != nil {
+		t.Fatalf("unexpected error updating account, error %v", err)
+	}
+
+	if got, want := updated.AccoundData.Version, 2; got != want {
+		t.Errorf("version does not match, expected %d got %d", want, got)
+	}
+}
+
+func TestFaultPlan_DuplicateRepliesPreviousResponse(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	api := newAPIClient(srv)
+
+	srv.Inject(NewFaultPlan(1, &Fault{Method: nethttp.MethodGet, Route: RouteAccounts, From: 2, To: 2, Duplicate: true}))
+
+	first, err := api.List(context.Background(), finn.NewPagination(0, 10))
+	if err != nil {
+		t.Fatalf("unexpected error listing accounts, error %v", err)
+	}
+
+	if _, err := api.Create(context.Background(), newAccount("GB")); err != nil {
+		t.Fatalf("unexpected error creating account, error %v", err)
+	}
+
+	second, err := api.List(context.Background(), finn.NewPagination(0, 10))
+	if err != nil {
+		t.Fatalf("unexpected error listing accounts, error %v", err)
+	}
+
+	if len(first.Accounts) != 0 || len(second.Accounts) != 0 {
+		t.Errorf("expected duplicated empty response, got %d and %d", len(first.Accounts), len(second.Accounts))
+	}
+}
+
+func TestFaultPlan_DuplicateKeepsAccountResponsesApart(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	api := newAPIClient(srv)
+
+	first, second := newAccount("GB"), newAccount("ES")
+	for _, acc := range []*finn.Account{first, second} {
+		if _, err := api.Create(context.Background(), acc); err != nil {
+			t.Fatalf("unexpected error creating account, error %v", err)
+		}
+	}
+
+	srv.Inject(NewFaultPlan(1, &Fault{Method: nethttp.MethodGet, Route: RouteAccount, Duplicate: true}))
+
+	for i := 0; i < 2; i++ {
+		for _, acc := range []*finn.Account{first, second} {
+			got, err := api.Fetch(context.Background(), acc.AccoundData.ID)
+			if err != nil {
+				t.Fatalf("unexpected error fetching account, error %v", err)
+			}
+
+			if got.AccoundData.ID != acc.AccoundData.ID {
+				t.Errorf("duplicated response of another account, expected %s got %s", acc.AccoundData.ID, got.AccoundData.ID)
+			}
+		}
+	}
+}
+
+func TestLatencyDistributionsStayOnBounds(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	u := UniformLatency{Min: time.Millisecond, Max: 2 * time.Millisecond}
+	n := NormalLatency{Mean: time.Millisecond, StdDev: 10 * time.Millisecond}
+
+	for i := 0; i < 100; i++ {
+		if d := u.Delay(r); d < u.Min || d >= u.Max {
+			t.Fatalf("uniform delay out of bounds, got %v", d)
+		}
+
+		if d := n.Delay(r); d < 0 {
+			t.Fatalf("normal delay is negative, got %v", d)
+		}
+	}
+}
+
+func TestFaultPlan_DroppedCreateResponseIsRecoveredByIdempotentRetry(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+
+	srv.Inject(NewFaultPlan(1, &Fault{Method: nethttp.MethodPost, To: 1, DropResponse: true}))
+
+	p := http.DefaultRetryPolicy()
+	p.BaseBackoff = time.Millisecond
+	api := finn.NewAPIClient(http.NewClientWithRetryPolicy(srv.BaseURL(), p))
+
+	acc := newAccount("GB")
+	created, err := api.Create(context.Background(), acc)
+	if err != nil {
+		t.Fatalf("unexpected error creating account, error %v", err)
+	}
+
+	if got, want := created.AccoundData.ID, acc.AccoundData.ID; got != want {
+		t.Errorf("account id does not match, expected %s got %s", want, got)
+	}
+
+	if got, want := srv.Len(), 1; got != want {
+		t.Errorf("unexpected stored accounts, expected %d got %d", want, got)
+	}
+}
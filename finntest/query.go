@@ -0,0 +1,147 @@
+package finntest
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/marcosQuesada/finn"
+	"github.com/marcosQuesada/finn/internal/strs"
+)
+
+// filters holds list filters by field, values on the same field are ORed
+type filters map[string][]string
+
+// parseFilters reads filter[field] query parameters
+func parseFilters(q url.Values) filters {
+	f := make(filters)
+	for k, values := range q {
+		if !strings.HasPrefix(k, "filter[") || !strings.HasSuffix(k, "]") {
+			continue
+		}
+
+		field := k[len("filter[") : len(k)-1]
+		for _, v := range values {
+			f[field] = append(f[field], strings.Split(v, ",")...)
+		}
+	}
+
+	return f
+}
+
+// match checks account against all filters
+func (f filters) match(a *finn.AccoundData) bool {
+	for field, values := range f {
+		if !strs.Contains(values, attribute(a, field)) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// query encodes filters as query parameters
+func (f filters) query(q url.Values) {
+	for field, values := range f {
+		q.Set(fmt.Sprintf("filter[%s]", field), strings.Join(values, ","))
+	}
+}
+
+// attribute gets filterable attribute value
+func attribute(a *finn.AccoundData, field string) string {
+	attr := a.Attributes
+	if attr == nil {
+		return ""
+	}
+
+	switch field {
+	case "bank_id":
+		return attr.BankID
+	case "bank_id_code":
+		return attr.BankIDCode
+	case "account_number":
+		return attr.AccountNumber
+	case "iban":
+		return attr.Iban
+	case "country":
+		return attr.Country
+	case "customer_id":
+		return attr.CustomerID
+	}
+
+	return ""
+}
+
+// page defines requested list page
+type page struct {
+	number int
+	size   int
+	last   int
+}
+
+// parsePage reads page[number] and page[size], number accepts first and last keywords
+func parsePage(q url.Values, total int) (*page, error) {
+	size, err := intParam(q.Get("page[size]"), defaultPageSize)
+	if err != nil || size <= 0 {
+		return nil, errors.New("invalid page size")
+	}
+
+	p := &page{size: size}
+	if total > 0 {
+		p.last = (total - 1) / size
+	}
+
+	switch n := q.Get("page[number]"); n {
+	case "first":
+		p.number = 0
+	case "last":
+		p.number = p.last
+	default:
+		p.number, err = intParam(n, 0)
+		if err != nil || p.number < 0 {
+			return nil, errors.New("invalid page number")
+		}
+	}
+
+	return p, nil
+}
+
+// links builds pagination links, next and prev are only set when those pages exist
+func (p *page) links(f filters) *finn.LinkList {
+	l := &finn.LinkList{
+		First: p.uri("first", f),
+		Last:  p.uri("last", f),
+		Self:  p.uri(strconv.Itoa(p.number), f),
+	}
+
+	if p.number < p.last {
+		l.Next = p.uri(strconv.Itoa(p.number+1), f)
+	}
+
+	if p.number > 0 {
+		l.Prev = p.uri(strconv.Itoa(p.number-1), f)
+	}
+
+	return l
+}
+
+// uri builds list page uri
+func (p *page) uri(number string, f filters) string {
+	q := url.Values{}
+	q.Set("page[number]", number)
+	q.Set("page[size]", strconv.Itoa(p.size))
+	f.query(q)
+
+	return fmt.Sprintf("%s?%s", accountsPath, q.Encode())
+}
+
+// intParam parses an integer query parameter, def is returned when empty
+func intParam(v string, def int) (int, error) {
+	if v == "" {
+		return def, nil
+	}
+
+	return strconv.Atoi(v)
+}
@@ -0,0 +1,381 @@
+package finntest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/marcosQuesada/finn"
+	"github.com/marcosQuesada/finn/signature"
+)
+
+const accountsPath = "/v1/organisation/accounts"
+const jsonContentType = "application/vnd.api+json"
+const defaultPageSize = 100
+
+// Server is an in-memory account api served through httptest, it implements organisation accounts
+// create, fetch, list, update and delete with version checks and pagination links
+type Server struct {
+	*httptest.Server
+	mutex    sync.Mutex
+	accounts []*finn.AccoundData
+	faults   *FaultPlan
+	verifier *signature.Verifier
+}
+
+// NewServer starts an in-memory account api server, Close has to be called when done
+func NewServer() *Server {
+	s := &Server{}
+	s.Server = httptest.NewServer(s)
+
+	return s
+}
+
+// BaseURL returns server url, ready to be used as http client base url
+func (s *Server) BaseURL() *url.URL {
+	u, _ := url.Parse(s.URL + "/")
+
+	return u
+}
+
+// Len returns stored accounts size
+func (s *Server) Len() int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return len(s.accounts)
+}
+
+// Reset removes all stored accounts
+func (s *Server) Reset() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.accounts = nil
+}
+
+// RequireSignatures rejects requests without a valid HTTP signature, nil verifier disables it
+func (s *Server) RequireSignatures(v *signature.Verifier) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.verifier = v
+}
+
+// ServeHTTP routes account api requests, applying injected faults and signature verification
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mutex.Lock()
+	faults, verifier := s.faults, s.verifier
+	s.mutex.Unlock()
+
+	if verifier != nil {
+		if err := verifier.Verify(r); err != nil {
+			writeError(w, http.StatusUnauthorized, err.Error())
+			return
+		}
+	}
+
+	if faults == nil {
+		s.route(w, r)
+		return
+	}
+
+	faults.serve(w, r, s.route)
+}
+
+// route dispatches account api requests
+func (s *Server) route(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path == accountsPath {
+		switch r.Method {
+		case http.MethodPost:
+			s.create(w, r)
+		case http.MethodGet:
+			s.list(w, r)
+		default:
+			writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		}
+		return
+	}
+
+	id := strings.TrimPrefix(r.URL.Path, accountsPath+"/")
+	if id == r.URL.Path || id == "" || strings.Contains(id, "/") {
+		writeError(w, http.StatusNotFound, "route not found")
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		s.fetch(w, id)
+	case http.MethodPatch:
+		s.update(w, r, id)
+	case http.MethodDelete:
+		s.delete(w, r, id)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
+
+// create stores a new account, duplicated ids are rejected
+func (s *Server) create(w http.ResponseWriter, r *http.Request) {
+	acc := &finn.Account{}
+	if err := json.NewDecoder(r.Body).Decode(acc); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid json body, error %v", err))
+		return
+	}
+
+	if msg := validate(acc); msg != "" {
+		writeError(w, http.StatusBadRequest, msg)
+		return
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.find(acc.AccoundData.ID) >= 0 {
+		writeError(w, http.StatusConflict, "Account cannot be created as it violates a duplicate constraint")
+		return
+	}
+
+	d := acc.AccoundData
+	d.Version = 0
+	s.accounts = append(s.accounts, d)
+
+	writeAccount(w, http.StatusCreated, d)
+}
+
+// fetch gets account by id
+func (s *Server) fetch(w http.ResponseWriter, id string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	i := s.find(id)
+	if i < 0 {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("record %s does not exist", id))
+		return
+	}
+
+	writeAccount(w, http.StatusOK, s.accounts[i])
+}
+
+// update merges patched attributes into stored account, version has to match stored one
+func (s *Server) update(w http.ResponseWriter, r *http.Request, id string) {
+	doc := &patchDocument{}
+	if err := json.NewDecoder(r.Body).Decode(doc); err != nil || doc.Data == nil {
+		writeError(w, http.StatusBadRequest, "invalid json body")
+		return
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	i := s.find(id)
+	if i < 0 {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("record %s does not exist", id))
+		return
+	}
+
+	stored := s.accounts[i]
+	if doc.Data.Version != stored.Version {
+		writeError(w, http.StatusConflict, "invalid version")
+		return
+	}
+
+	attr, err := merge(stored.Attributes, doc.Data.Attributes)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid attributes, error %v", err))
+		return
+	}
+
+	updated := *stored
+	updated.Attributes = attr
+	updated.Version++
+	s.accounts[i] = &updated
+
+	writeAccount(w, http.StatusOK, &updated)
+}
+
+// delete removes account by id when version matches
+func (s *Server) delete(w http.ResponseWriter, r *http.Request, id string) {
+	version, err := intParam(r.URL.Query().Get("version"), -1)
+	if err != nil || version < 0 {
+		writeError(w, http.StatusBadRequest, "invalid version number")
+		return
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	i := s.find(id)
+	if i < 0 {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("record %s does not exist", id))
+		return
+	}
+
+	if s.accounts[i].Version != version {
+		writeError(w, http.StatusConflict, "invalid version")
+		return
+	}
+
+	s.accounts = append(s.accounts[:i], s.accounts[i+1:]...)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// list returns a filtered accounts page with JSON:API pagination links
+func (s *Server) list(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	filters := parseFilters(q)
+
+	s.mutex.Lock()
+	var matches []*finn.AccoundData
+	for _, a := range s.accounts {
+		if filters.match(a) {
+			matches = append(matches, a)
+		}
+	}
+	s.mutex.Unlock()
+
+	p, err := parsePage(q, len(matches))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	from, to := p.number*p.size, (p.number+1)*p.size
+	if from > len(matches) {
+		from = len(matches)
+	}
+	if to > len(matches) {
+		to = len(matches)
+	}
+
+	page := make([]*finn.AccoundData, 0, to-from)
+	page = append(page, matches[from:to]...)
+
+	writeJSON(w, http.StatusOK, &finn.AccountList{
+		Accounts: page,
+		Links:    p.links(filters),
+	})
+}
+
+// find returns account index by id, -1 when not found, lock has to be held
+func (s *Server) find(id string) int {
+	for i, a := range s.accounts {
+		if a.ID == id {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// validate applies account api creation rules, returns violation message
+func validate(acc *finn.Account) string {
+	d := acc.AccoundData
+	if d == nil {
+		return "data is required"
+	}
+
+	var msgs []string
+	if d.Type != "accounts" {
+		msgs = append(msgs, "type in body should be one of [accounts]")
+	}
+
+	if _, err := uuid.Parse(d.ID); err != nil {
+		msgs = append(msgs, "id in body must be of type uuid")
+	}
+
+	if _, err := uuid.Parse(d.OrganisationID); err != nil {
+		msgs = append(msgs, "organisation_id in body must be of type uuid")
+	}
+
+	if d.Attributes == nil || d.Attributes.Country == "" {
+		msgs = append(msgs, "country in body is required")
+	}
+
+	return strings.Join(msgs, "\n")
+}
+
+// merge overlays patched attributes, as sent on partial documents, over stored ones, null values
+// clear stored fields
+func merge(stored *finn.Attributes, patch map[string]json.RawMessage) (*finn.Attributes, error) {
+	fields := make(map[string]json.RawMessage)
+	if stored != nil {
+		raw, err := json.Marshal(stored)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := json.Unmarshal(raw, &fields); err != nil {
+			return nil, err
+		}
+	}
+
+	for k, v := range patch {
+		if string(v) == "null" {
+			delete(fields, k)
+			continue
+		}
+		fields[k] = v
+	}
+
+	raw, err := json.Marshal(fields)
+	if err != nil {
+		return nil, err
+	}
+
+	attr := &finn.Attributes{}
+	if err := json.Unmarshal(raw, attr); err != nil {
+		return nil, err
+	}
+
+	return attr, nil
+}
+
+// accountDocument defines single account response document
+type accountDocument struct {
+	Data  *finn.AccoundData `json:"data"`
+	Links *selfLink         `json:"links"`
+}
+
+// patchDocument defines a partial account document, attributes hold patched fields only
+type patchDocument struct {
+	Data *struct {
+		Version    int                        `json:"version"`
+		Attributes map[string]json.RawMessage `json:"attributes"`
+	} `json:"data"`
+}
+
+// selfLink defines single resource links
+type selfLink struct {
+	Self string `json:"self"`
+}
+
+// errorDocument defines account api error payload
+type errorDocument struct {
+	ErrorMessage string `json:"error_message"`
+}
+
+// writeAccount writes account document
+func writeAccount(w http.ResponseWriter, status int, d *finn.AccoundData) {
+	writeJSON(w, status, &accountDocument{
+		Data:  d,
+		Links: &selfLink{Self: fmt.Sprintf("%s/%s", accountsPath, d.ID)},
+	})
+}
+
+// writeError writes account api error payload
+func writeError(w http.ResponseWriter, status int, msg string) {
+	writeJSON(w, status, &errorDocument{ErrorMessage: msg})
+}
+
+// writeJSON writes json encoded v with status code
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", jsonContentType)
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
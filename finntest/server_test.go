@@ -0,0 +1,291 @@
+package finntest
+
+import (
+	"context"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/marcosQuesada/finn"
+	"github.com/marcosQuesada/finn/http"
+	"github.com/marcosQuesada/finn/signature"
+)
+
+func TestServer_CreateAndFetchAccount(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	api := newAPIClient(srv)
+
+	acc := newAccount("GB")
+	created, err := api.Create(context.Background(), acc)
+	if err != nil {
+		t.Fatalf("unexpected error creating account, error %v", err)
+	}
+
+	if got, want := created.AccoundData.ID, acc.AccoundData.ID; got != want {
+		t.Errorf("account id does not match, expected %s got %s", want, got)
+	}
+
+	fetched, err := api.Fetch(context.Background(), acc.AccoundData.ID)
+	if err != nil {
+		t.Fatalf("unexpected error fetching account, error %v", err)
+	}
+
+	if got, want := fetched.AccoundData.Attributes.Country, "GB"; got != want {
+		t.Errorf("account country does not match, expected %s got %s", want, got)
+	}
+}
+
+func TestServer_CreateRejectsDuplicatedAndInvalidAccounts(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	api := newAPIClient(srv)
+
+	acc := newAccount("GB")
+	if _, err := api.Create(context.Background(), acc); err != nil {
+		t.Fatalf("unexpected error creating account, error %v", err)
+	}
+
+	if _, err := api.Create(context.Background(), acc); err != nil {
+		t.Errorf("unexpected error on replayed account creation, error %v", err)
+	}
+
+	acc.AccoundData.Attributes.Country = "ES"
+	_, err := api.Create(context.Background(), acc)
+	if !errors.Is(err, finn.ErrDuplicateAccount) {
+		t.Errorf("unexpected error type, expected duplicate account got %v", err)
+	}
+
+	invalid := newAccount("")
+	invalid.AccoundData.OrganisationID = "fakeOrganisationID"
+	_, err = api.Create(context.Background(), invalid)
+	if !errors.Is(err, http.ErrBadRequest) {
+		t.Fatalf("unexpected error type, expected bad request got %v", err)
+	}
+
+	var apiErr *http.APIError
+	if !errors.As(err, &apiErr) || len(apiErr.Errors) != 1 || apiErr.Errors[0].Detail == "" {
+		t.Errorf("expected api error with error message, got %v", err)
+	}
+
+	if got, want := srv.Len(), 1; got != want {
+		t.Errorf("unexpected stored accounts, expected %d got %d", want, got)
+	}
+}
+
+func TestServer_FetchReturnsNotFoundOnUnknownAccount(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	api := newAPIClient(srv)
+
+	_, err := api.Fetch(context.Background(), uuid.New().String())
+	if !errors.Is(err, http.ErrContentNotFound) {
+		t.Errorf("unexpected error type, expected not found got %v", err)
+	}
+}
+
+func TestServer_DeleteChecksVersion(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	api := newAPIClient(srv)
+
+	acc := newAccount("GB")
+	if _, err := api.Create(context.Background(), acc); err != nil {
+		t.Fatalf("unexpected error creating account, error %v", err)
+	}
+
+	err := api.Delete(context.Background(), acc.AccoundData.ID, 1)
+	if !errors.Is(err, finn.ErrVersionConflict) {
+		t.Errorf("unexpected error type, expected conflict got %v", err)
+	}
+
+	err = api.Delete(context.Background(), uuid.New().String(), 0)
+	if !errors.Is(err, http.ErrContentNotFound) {
+		t.Errorf("unexpected error type, expected not found got %v", err)
+	}
+
+	if err := api.Delete(context.Background(), acc.AccoundData.ID, 0); err != nil {
+		t.Fatalf("unexpected error deleting account, error %v", err)
+	}
+
+	if got := srv.Len(); got != 0 {
+		t.Errorf("unexpected stored accounts, got %d", got)
+	}
+}
+
+func TestServer_UpdateMergesAttributesAndIncrementsVersion(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	api := newAPIClient(srv)
+
+	acc := newAccount("GB")
+	if _, err := api.Create(context.Background(), acc); err != nil {
+		t.Fatalf("unexpected error creating account, error %v", err)
+	}
+
+	patch := &finn.Account{AccoundData: &finn.AccoundData{
+		Type:       "accounts",
+		ID:         acc.AccoundData.ID,
+		Version:    0,
+		Attributes: &finn.Attributes{Status: "closed"},
+	}}
+	updated, err := api.Update(context.Background(), patch)
+	if err != nil {
+		t.Fatalf("unexpected error updating account, error %v", err)
+	}
+
+	if got, want := updated.AccoundData.Version, 1; got != want {
+		t.Errorf("version does not match, expected %d got %d", want, got)
+	}
+
+	if updated.AccoundData.Attributes.Status != "closed" || updated.AccoundData.Attributes.Country != "GB" {
+		t.Errorf("unexpected merged attributes, got %+v", updated.AccoundData.Attributes)
+	}
+
+	_, err = api.Update(context.Background(), patch)
+	if !errors.Is(err, finn.ErrVersionConflict) {
+		t.Errorf("unexpected error type, expected conflict got %v", err)
+	}
+}
+
+func TestServer_FetchAndUpdateClearsRemovedAttributes(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	api := newAPIClient(srv)
+
+	acc := newAccount("GB")
+	acc.AccoundData.Attributes.Status = "confirmed"
+	acc.AccoundData.Attributes.Name = []string{"Samantha Holder"}
+	if _, err := api.Create(context.Background(), acc); err != nil {
+		t.Fatalf("unexpected error creating account, error %v", err)
+	}
+
+	updated, err := api.FetchAndUpdate(context.Background(), acc.AccoundData.ID, 1, func(a *finn.Account) error {
+		a.AccoundData.Attributes.Status = ""
+		a.AccoundData.Attributes.Name = []string{"Sam Holder"}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error updating account, error %v", err)
+	}
+
+	attr := updated.AccoundData.Attributes
+	if attr.Status != "" || len(attr.Name) != 1 || attr.Name[0] != "Sam Holder" || attr.Country != "GB" {
+		t.Errorf("unexpected merged attributes, got %+v", attr)
+	}
+}
+
+func TestServer_ListPaginatesWithLinks(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	api := newAPIClient(srv)
+
+	for i := 0; i < 25; i++ {
+		if _, err := api.Create(context.Background(), newAccount("ES")); err != nil {
+			t.Fatalf("unexpected error creating account, error %v", err)
+		}
+	}
+
+	list, err := api.List(context.Background(), finn.NewPagination(2, 10))
+	if err != nil {
+		t.Fatalf("unexpected error listing accounts, error %v", err)
+	}
+
+	if got, want := len(list.Accounts), 5; got != want {
+		t.Errorf("unexpected page size, expected %d got %d", want, got)
+	}
+
+	if list.Links.Next != "" || list.Links.Prev == "" {
+		t.Errorf("unexpected last page links, got %+v", list.Links)
+	}
+
+	count := 0
+	it := api.ListAll(context.Background(), finn.NewPagination(0, 10))
+	for it.Next() {
+		count++
+	}
+
+	if err := it.Err(); err != nil {
+		t.Fatalf("unexpected iteration error, error %v", err)
+	}
+
+	if got, want := count, 25; got != want {
+		t.Errorf("unexpected iterated accounts, expected %d got %d", want, got)
+	}
+}
+
+func TestServer_ListAppliesFilters(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	api := newAPIClient(srv)
+
+	for _, country := range []string{"GB", "ES", "FR", "GB"} {
+		if _, err := api.Create(context.Background(), newAccount(country)); err != nil {
+			t.Fatalf("unexpected error creating account, error %v", err)
+		}
+	}
+
+	opts := finn.NewListOptions(finn.NewPagination(0, 1), finn.NewFilter().Country("GB", "FR"))
+	count := 0
+	it := api.ListAll(context.Background(), opts)
+	for it.Next() {
+		if c := it.Account().Attributes.Country; c == "ES" {
+			t.Errorf("unexpected filtered country, got %s", c)
+		}
+		count++
+	}
+
+	if err := it.Err(); err != nil {
+		t.Fatalf("unexpected iteration error, error %v", err)
+	}
+
+	if got, want := count, 3; got != want {
+		t.Errorf("unexpected filtered accounts, expected %d got %d", want, got)
+	}
+}
+
+func newAPIClient(srv *Server) *finn.APIClient {
+	return finn.NewAPIClient(http.NewClientWithUrl(srv.BaseURL()))
+}
+
+func newAccount(country string) *finn.Account {
+	return &finn.Account{
+		AccoundData: &finn.AccoundData{
+			Type:           "accounts",
+			ID:             uuid.New().String(),
+			OrganisationID: uuid.New().String(),
+			Attributes: &finn.Attributes{
+				Country: country,
+			},
+		},
+	}
+}
+
+func TestServer_RequireSignaturesRejectsUnsignedRequests(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+
+	pub, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("unexpected error generating key, error %v", err)
+	}
+
+	signer, err := signature.NewSigner("fakeKeyID", key)
+	if err != nil {
+		t.Fatalf("unexpected error creating signer, error %v", err)
+	}
+	srv.RequireSignatures(signature.NewVerifier(signature.StaticKeys(map[string]crypto.PublicKey{"fakeKeyID": pub})))
+
+	_, err = newAPIClient(srv).Create(context.Background(), newAccount("GB"))
+	if !errors.Is(err, http.ErrUnauthorized) {
+		t.Errorf("unexpected error type, expected unauthorized got %v", err)
+	}
+
+	api := finn.NewAPIClient(http.NewClientWithUrl(srv.BaseURL(), http.WithMiddleware(signer.Middleware())))
+	if _, err := api.Create(context.Background(), newAccount("GB")); err != nil {
+		t.Errorf("unexpected error on signed request, error %v", err)
+	}
+}
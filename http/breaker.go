@@ -0,0 +1,360 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen happens when circuit breaker rejects a request without sending it
+var ErrCircuitOpen = errors.New("circuit open")
+
+// CircuitState defines circuit breaker state
+type CircuitState int
+
+const (
+	// StateClosed lets every request through, tracking failures
+	StateClosed CircuitState = iota
+	// StateOpen rejects every request until open timeout elapses
+	StateOpen
+	// StateHalfOpen lets a limited number of probe requests through
+	StateHalfOpen
+)
+
+// String returns state name
+func (s CircuitState) String() string {
+	switch s {
+	case StateClosed:
+		return "closed"
+	case StateOpen:
+		return "open"
+	case StateHalfOpen:
+		return "half-open"
+	}
+
+	return "unknown"
+}
+
+// BreakerConfig defines when circuits trip and recover, zero values take defaults
+type BreakerConfig struct {
+	// FailureRatio trips the circuit once failures reach it within Window, 0.5 by default
+	FailureRatio float64
+	// MinRequests within Window before the ratio is evaluated, 10 by default
+	MinRequests int
+	// Window is the rolling window failures are counted on, 10 seconds by default
+	Window time.Duration
+	// WindowBuckets splits Window in buckets expiring one at a time, 10 by default
+	WindowBuckets int
+	// OpenTimeout is how long an open circuit rejects requests before probing, 30 seconds by default
+	OpenTimeout time.Duration
+	// HalfOpenProbes bounds concurrent probes, as many successful probes close the circuit, 1 by default
+	HalfOpenProbes int
+	// IsFailure classifies an attempt outcome, by default transport errors and 5xx responses are
+	// failures, caller cancellations are neither failures nor successes and never reach it
+	IsFailure func(resp *http.Response, err error) bool
+	// OnStateChange is called on every circuit transition, outside breaker locks
+	OnStateChange func(baseURL string, from, to CircuitState)
+}
+
+// CircuitBreaker keeps a circuit per request base url (scheme and host), it is shareable across clients
+type CircuitBreaker struct {
+	cfg      BreakerConfig
+	mu       sync.Mutex
+	circuits map[string]*circuit
+	now      func() time.Time
+}
+
+// circuit holds a base url breaker state
+type circuit struct {
+	state    CircuitState
+	window   *window
+	openedAt time.Time
+	probes   int
+	passed   int
+	// generation changes on every transition, outcomes of attempts admitted on earlier ones are ignored
+	generation uint64
+}
+
+// ticket describes an admitted attempt, half-open probes are flagged
+type ticket struct {
+	probe      bool
+	generation uint64
+}
+
+// outcome classifies an attempt result
+type outcome int
+
+const (
+	// outcomeSuccess counts as a passed attempt
+	outcomeSuccess outcome = iota
+	// outcomeFailure counts as a failed attempt
+	outcomeFailure
+	// outcomeIgnored frees attempt probe slot without counting it, as on caller cancellation
+	outcomeIgnored
+)
+
+// transition describes a state change to notify
+type transition struct {
+	from, to CircuitState
+}
+
+// NewCircuitBreaker instantiates a circuit breaker
+func NewCircuitBreaker(cfg BreakerConfig) *CircuitBreaker {
+	if cfg.FailureRatio <= 0 {
+		cfg.FailureRatio = 0.5
+	}
+
+	if cfg.MinRequests <= 0 {
+		cfg.MinRequests = 10
+	}
+
+	if cfg.Window <= 0 {
+		cfg.Window = 10 * time.Second
+	}
+
+	if cfg.WindowBuckets <= 0 {
+		cfg.WindowBuckets = 10
+	}
+
+	if cfg.OpenTimeout <= 0 {
+		cfg.OpenTimeout = 30 * time.Second
+	}
+
+	if cfg.HalfOpenProbes <= 0 {
+		cfg.HalfOpenProbes = 1
+	}
+
+	if cfg.IsFailure == nil {
+		cfg.IsFailure = isFailure
+	}
+
+	return &CircuitBreaker{
+		cfg:      cfg,
+		circuits: make(map[string]*circuit),
+		now:      time.Now,
+	}
+}
+
+// WithCircuitBreaker guards each request attempt with b
+func WithCircuitBreaker(b *CircuitBreaker) Option {
+	return WithMiddleware(b.Middleware())
+}
+
+// Middleware rejects attempts with ErrCircuitOpen while request base url circuit is open
+// and records attempt outcomes
+func (b *CircuitBreaker) Middleware() Middleware {
+	return func(next RoundTripFunc) RoundTripFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			key := baseURLOf(req)
+			tk, err := b.allow(key)
+			if err != nil {
+				return nil, err
+			}
+
+			resp, err := next(req)
+			b.record(key, tk, b.outcome(resp, err))
+
+			return resp, err
+		}
+	}
+}
+
+// State returns base url circuit state, base url is scheme and host as in http://accountapi:8080
+func (b *CircuitBreaker) State(baseURL string) CircuitState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	c, ok := b.circuits[baseURL]
+	if !ok {
+		return StateClosed
+	}
+
+	return c.state
+}
+
+// outcome classifies attempt result, caller cancellations are ignored whatever IsFailure says
+func (b *CircuitBreaker) outcome(resp *http.Response, err error) outcome {
+	if errors.Is(err, context.Canceled) {
+		return outcomeIgnored
+	}
+
+	if b.cfg.IsFailure(resp, err) {
+		return outcomeFailure
+	}
+
+	return outcomeSuccess
+}
+
+// allow admits an attempt, flagging half-open probes
+func (b *CircuitBreaker) allow(key string) (ticket, error) {
+	b.mu.Lock()
+	c := b.circuit(key)
+	now := b.now()
+	var changed *transition
+
+	if c.state == StateOpen {
+		if wait := c.openedAt.Add(b.cfg.OpenTimeout).Sub(now); wait > 0 {
+			b.mu.Unlock()
+			return ticket{}, fmt.Errorf("%s retry in %v, error %w", key, wait, ErrCircuitOpen)
+		}
+		changed = b.set(c, StateHalfOpen, now)
+	}
+
+	probe := c.state == StateHalfOpen
+	if probe {
+		if c.probes >= b.cfg.HalfOpenProbes {
+			b.mu.Unlock()
+			b.notify(key, changed)
+			return ticket{}, fmt.Errorf("%s probing, error %w", key, ErrCircuitOpen)
+		}
+		c.probes++
+	}
+	tk := ticket{probe: probe, generation: c.generation}
+	b.mu.Unlock()
+	b.notify(key, changed)
+
+	return tk, nil
+}
+
+// record accounts attempt outcome, tripping or closing the circuit, outcomes of attempts admitted
+// before the last transition are ignored, ignored outcomes only free probe slots
+func (b *CircuitBreaker) record(key string, tk ticket, o outcome) {
+	b.mu.Lock()
+	c := b.circuit(key)
+	now := b.now()
+	var changed *transition
+
+	switch {
+	case tk.generation != c.generation:
+	case tk.probe && c.state == StateHalfOpen:
+		c.probes--
+		if o == outcomeIgnored {
+			break
+		}
+
+		if o == outcomeFailure {
+			changed = b.set(c, StateOpen, now)
+			break
+		}
+
+		c.passed++
+		if c.passed >= b.cfg.HalfOpenProbes {
+			changed = b.set(c, StateClosed, now)
+		}
+	case !tk.probe && c.state == StateClosed && o != outcomeIgnored:
+		c.window.add(now, o == outcomeFailure)
+		total, failures := c.window.counts(now)
+		if total >= b.cfg.MinRequests && float64(failures)/float64(total) >= b.cfg.FailureRatio {
+			changed = b.set(c, StateOpen, now)
+		}
+	}
+	b.mu.Unlock()
+
+	b.notify(key, changed)
+}
+
+// circuit returns key circuit, creating it closed
+func (b *CircuitBreaker) circuit(key string) *circuit {
+	c, ok := b.circuits[key]
+	if !ok {
+		c = &circuit{window: newWindow(b.cfg.Window, b.cfg.WindowBuckets)}
+		b.circuits[key] = c
+	}
+
+	return c
+}
+
+// set moves circuit to state resetting its counters
+func (b *CircuitBreaker) set(c *circuit, state CircuitState, now time.Time) *transition {
+	t := &transition{from: c.state, to: state}
+	c.state = state
+	c.probes, c.passed = 0, 0
+	c.generation++
+	if state == StateOpen {
+		c.openedAt = now
+	}
+
+	if state == StateClosed {
+		c.window = newWindow(b.cfg.Window, b.cfg.WindowBuckets)
+	}
+
+	return t
+}
+
+// notify calls state change callback
+func (b *CircuitBreaker) notify(key string, t *transition) {
+	if t != nil && b.cfg.OnStateChange != nil {
+		b.cfg.OnStateChange(key, t.from, t.to)
+	}
+}
+
+// isFailure flags transport errors, but caller cancellation, and 5xx responses
+func isFailure(resp *http.Response, err error) bool {
+	if err != nil {
+		return !errors.Is(err, context.Canceled)
+	}
+
+	return resp.StatusCode >= http.StatusInternalServerError
+}
+
+// baseURLOf returns request scheme and host
+func baseURLOf(req *http.Request) string {
+	return req.URL.Scheme + "://" + req.URL.Host
+}
+
+// window counts outcomes over a rolling time window split in buckets
+type window struct {
+	size    time.Duration
+	buckets []windowBucket
+}
+
+// windowBucket counts outcomes on a bucket sized slot
+type windowBucket struct {
+	slot     int64
+	total    int
+	failures int
+}
+
+// newWindow instantiates a rolling window of d duration split in buckets
+func newWindow(d time.Duration, buckets int) *window {
+	size := d / time.Duration(buckets)
+	if size <= 0 {
+		size = 1
+	}
+
+	return &window{
+		size:    size,
+		buckets: make([]windowBucket, buckets),
+	}
+}
+
+// add counts an outcome on current slot bucket
+func (w *window) add(now time.Time, failed bool) {
+	slot := now.UnixNano() / int64(w.size)
+	b := &w.buckets[slot%int64(len(w.buckets))]
+	if b.slot != slot {
+		*b = windowBucket{slot: slot}
+	}
+
+	b.total++
+	if failed {
+		b.failures++
+	}
+}
+
+// counts sums outcomes within window
+func (w *window) counts(now time.Time) (int, int) {
+	slot := now.UnixNano() / int64(w.size)
+	var total, failures int
+	for _, b := range w.buckets {
+		if slot-b.slot < int64(len(w.buckets)) {
+			total += b.total
+			failures += b.failures
+		}
+	}
+
+	return total, failures
+}
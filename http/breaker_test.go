@@ -0,0 +1,281 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// breakerServer answers 500 while down is set
+type breakerServer struct {
+	*httptest.Server
+	down  int32
+	calls int32
+}
+
+func newBreakerServer() *breakerServer {
+	s := &breakerServer{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&s.calls, 1)
+		if atomic.LoadInt32(&s.down) == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+
+	return s
+}
+
+func (s *breakerServer) setDown(down bool) {
+	v := int32(0)
+	if down {
+		v = 1
+	}
+	atomic.StoreInt32(&s.down, v)
+}
+
+// transitions records state changes
+type transitions struct {
+	mu     sync.Mutex
+	states []string
+}
+
+func (t *transitions) record(_ string, from, to CircuitState) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.states = append(t.states, from.String()+">"+to.String())
+}
+
+func TestCircuitBreaker_TripsProbesAndCloses(t *testing.T) {
+	srv := newBreakerServer()
+	defer srv.Close()
+	srv.setDown(true)
+
+	now := time.Now()
+	tr := &transitions{}
+	b := NewCircuitBreaker(BreakerConfig{
+		FailureRatio:   0.5,
+		MinRequests:    4,
+		OpenTimeout:    time.Minute,
+		HalfOpenProbes: 2,
+		OnStateChange:  tr.record,
+	})
+	b.now = func() time.Time { return now }
+
+	u, _ := url.Parse(srv.URL)
+	c := NewClientWithUrl(u, WithCircuitBreaker(b))
+	do := func() error {
+		req, _ := c.CreateRequest(http.MethodGet, "v1/organisation/accounts", nil)
+		_, err := c.Do(context.Background(), req, nil)
+		return err
+	}
+
+	for i := 0; i < 4; i++ {
+		if err := do(); !errors.Is(err, ErrInternalServer) {
+			t.Fatalf("unexpected error type, expected internal server got %v", err)
+		}
+	}
+
+	if got := b.State(srv.URL); got != StateOpen {
+		t.Fatalf("circuit not tripped, got %s", got)
+	}
+
+	if err := do(); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("unexpected error type, expected circuit open got %v", err)
+	}
+
+	if got := atomic.LoadInt32(&srv.calls); got != 4 {
+		t.Errorf("open circuit let requests through, expected 4 calls got %d", got)
+	}
+
+	now = now.Add(time.Minute)
+	if err := do(); !errors.Is(err, ErrInternalServer) {
+		t.Errorf("unexpected error type on failed probe, expected internal server got %v", err)
+	}
+
+	if got := b.State(srv.URL); got != StateOpen {
+		t.Fatalf("failed probe did not reopen circuit, got %s", got)
+	}
+
+	srv.setDown(false)
+	now = now.Add(time.Minute)
+	for i := 0; i < 2; i++ {
+		if err := do(); err != nil {
+			t.Fatalf("unexpected error on probe, error %v", err)
+		}
+	}
+
+	if got := b.State(srv.URL); got != StateClosed {
+		t.Errorf("successful probes did not close circuit, got %s", got)
+	}
+
+	expected := []string{"closed>open", "open>half-open", "half-open>open", "open>half-open", "half-open>closed"}
+	if len(tr.states) != len(expected) {
+		t.Fatalf("transitions do not match, expected %v got %v", expected, tr.states)
+	}
+
+	for i := range expected {
+		if tr.states[i] != expected[i] {
+			t.Errorf("transition %d does not match, expected %s got %s", i, expected[i], tr.states[i])
+		}
+	}
+}
+
+func TestCircuitBreaker_FailuresExpireFromRollingWindow(t *testing.T) {
+	now := time.Now()
+	b := NewCircuitBreaker(BreakerConfig{MinRequests: 4, Window: 10 * time.Second})
+	b.now = func() time.Time { return now }
+
+	key := "http://accountapi:8080"
+	for i := 0; i < 3; i++ {
+		b.record(key, ticket{}, outcomeFailure)
+	}
+
+	now = now.Add(11 * time.Second)
+	b.record(key, ticket{}, outcomeFailure)
+	if got := b.State(key); got != StateClosed {
+		t.Errorf("expired failures tripped circuit, got %s", got)
+	}
+
+	for i := 0; i < 4; i++ {
+		out := outcomeSuccess
+		if i%2 == 0 {
+			out = outcomeFailure
+		}
+		b.record(key, ticket{}, out)
+	}
+
+	if got := b.State(key); got != StateOpen {
+		t.Errorf("failure ratio within window did not trip circuit, got %s", got)
+	}
+}
+
+func TestCircuitBreaker_LimitsConcurrentProbes(t *testing.T) {
+	now := time.Now()
+	b := NewCircuitBreaker(BreakerConfig{MinRequests: 1, OpenTimeout: time.Second, HalfOpenProbes: 1})
+	b.now = func() time.Time { return now }
+
+	key := "http://accountapi:8080"
+	b.record(key, ticket{}, outcomeFailure)
+	now = now.Add(time.Second)
+
+	tk, err := b.allow(key)
+	if err != nil || !tk.probe {
+		t.Fatalf("expected probe to be admitted, probe %v error %v", tk.probe, err)
+	}
+
+	if _, err := b.allow(key); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("unexpected error type on exceeding probes, expected circuit open got %v", err)
+	}
+}
+
+func TestCircuitBreaker_IgnoresProbesFromEarlierGenerations(t *testing.T) {
+	now := time.Now()
+	b := NewCircuitBreaker(BreakerConfig{MinRequests: 1, OpenTimeout: time.Second, HalfOpenProbes: 2})
+	b.now = func() time.Time { return now }
+
+	key := "http://accountapi:8080"
+	b.record(key, ticket{}, outcomeFailure)
+	now = now.Add(time.Second)
+
+	late, err := b.allow(key)
+	if err != nil {
+		t.Fatalf("unexpected error admitting probe, error %v", err)
+	}
+
+	failing, err := b.allow(key)
+	if err != nil {
+		t.Fatalf("unexpected error admitting probe, error %v", err)
+	}
+	b.record(key, failing, outcomeFailure)
+	now = now.Add(time.Second)
+
+	if _, err := b.allow(key); err != nil {
+		t.Fatalf("unexpected error admitting probe, error %v", err)
+	}
+
+	b.record(key, late, outcomeSuccess)
+
+	if _, err := b.allow(key); err != nil {
+		t.Fatalf("unexpected error admitting probe, error %v", err)
+	}
+
+	if _, err := b.allow(key); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("late probe result released extra probes, got %v", err)
+	}
+
+	if got := b.State(key); got != StateHalfOpen {
+		t.Errorf("late probe result changed state, got %s", got)
+	}
+}
+
+func TestCircuitBreaker_CancelledProbeKeepsCircuitHalfOpen(t *testing.T) {
+	now := time.Now()
+	b := NewCircuitBreaker(BreakerConfig{MinRequests: 1, OpenTimeout: time.Second, HalfOpenProbes: 1})
+	b.now = func() time.Time { return now }
+
+	key := "http://accountapi:8080"
+	b.record(key, ticket{}, outcomeFailure)
+	now = now.Add(time.Second)
+
+	cancelled := b.Middleware()(func(req *http.Request) (*http.Response, error) {
+		return nil, context.Canceled
+	})
+	req, _ := http.NewRequest(http.MethodGet, key+"/v1/organisation/accounts", nil)
+	if _, err := cancelled(req); !errors.Is(err, context.Canceled) {
+		t.Fatalf("unexpected error type, expected canceled got %v", err)
+	}
+
+	if got := b.State(key); got != StateHalfOpen {
+		t.Fatalf("cancelled probe changed state, got %s", got)
+	}
+
+	if _, err := b.allow(key); err != nil {
+		t.Errorf("cancelled probe did not free its slot, error %v", err)
+	}
+}
+
+func TestCircuitBreaker_CancellationsDoNotDiluteFailureRatio(t *testing.T) {
+	b := NewCircuitBreaker(BreakerConfig{MinRequests: 2, FailureRatio: 1})
+
+	key := "http://accountapi:8080"
+	b.record(key, ticket{}, outcomeFailure)
+	b.record(key, ticket{}, outcomeIgnored)
+	b.record(key, ticket{}, outcomeFailure)
+
+	if got := b.State(key); got != StateOpen {
+		t.Errorf("cancellations counted on failure ratio, got %s", got)
+	}
+}
+
+func TestCircuitBreaker_ScopedPerBaseURL(t *testing.T) {
+	b := NewCircuitBreaker(BreakerConfig{MinRequests: 1})
+	b.record("http://down:8080", ticket{}, outcomeFailure)
+
+	if _, err := b.allow("http://down:8080"); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("unexpected error type, expected circuit open got %v", err)
+	}
+
+	if _, err := b.allow("http://up:8080"); err != nil {
+		t.Errorf("unexpected error on healthy base url, error %v", err)
+	}
+}
+
+func TestCircuitBreaker_IgnoresCallerCancellation(t *testing.T) {
+	if isFailure(nil, context.Canceled) {
+		t.Error("caller cancellation counted as failure")
+	}
+
+	if !isFailure(nil, context.DeadlineExceeded) {
+		t.Error("deadline exceeded not counted as failure")
+	}
+
+	if isFailure(&http.Response{StatusCode: http.StatusNotFound}, nil) {
+		t.Error("4xx response counted as failure")
+	}
+}
@@ -3,14 +3,22 @@ package http
 import (
 	"bytes"
 	"context"
+	"crypto/tls"
 	"encoding/json"
 	"errors"
+	"io"
+	"io/ioutil"
 	"net/http"
 	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/marcosQuesada/finn/trace"
 )
 
 const defaultBaseURL = "http://accountapi:8080/"
 const jsonContentType = "application/vnd.api+json"
+const maxErrorBodySize = 1 << 20
 
 // ErrContentNotFound happens on 404 response status code
 var ErrContentNotFound = errors.New("content not found")
@@ -24,58 +32,177 @@ var ErrBadRequest = errors.New("bad request")
 // ErrInternalServer happens on not controlled error
 var ErrInternalServer = errors.New("internal server error")
 
+// ErrConflict happens on resource conflict, as version mismatch or duplicated resource
+var ErrConflict = errors.New("conflict")
+
+// ErrTooManyRequests happens when server throttles requests
+var ErrTooManyRequests = errors.New("too many requests")
+
+// ErrUnauthorized happens on missing or invalid credentials
+var ErrUnauthorized = errors.New("unauthorized")
+
+// ErrServiceUnavailable happens when server is temporarily unable to handle requests
+var ErrServiceUnavailable = errors.New("service unavailable")
+
 // Client takes care on the whole http execution
 type Client struct {
-	client  *http.Client
-	baseURL *url.URL
+	client      *http.Client
+	baseURL     *url.URL
+	retry       *RetryPolicy
+	middlewares []Middleware
+	userAgent   string
+	tracer      trace.Tracer
+	tlsConfig   *tls.Config
+	tlsErr      error
+	send        RoundTripFunc
 }
 
 // NewClient creates an http client that points to default base url
-func NewClient() *Client {
+func NewClient(opts ...Option) *Client {
 	baseUrl, _ := url.Parse(defaultBaseURL)
 
-	return &Client{
-		client:  &http.Client{},
-		baseURL: baseUrl,
-	}
+	return newClient(append([]Option{WithBaseURL(baseUrl)}, opts...))
 }
 
 // NewClientWithUrl creates an http client with specific url
-func NewClientWithUrl(u *url.URL) *Client {
-	return &Client{
-		client:  &http.Client{},
-		baseURL: u,
+func NewClientWithUrl(u *url.URL, opts ...Option) *Client {
+	return newClient(append([]Option{WithBaseURL(u)}, opts...))
+}
+
+// NewClientWithRetryPolicy creates an http client with specific url that retries failed requests
+func NewClientWithRetryPolicy(u *url.URL, p *RetryPolicy, opts ...Option) *Client {
+	return newClient(append([]Option{WithBaseURL(u), WithRetryPolicy(p)}, opts...))
+}
+
+// newClient applies options and builds middleware chain
+func newClient(opts []Option) *Client {
+	c := &Client{
+		client: &http.Client{},
+		tracer: trace.Noop{},
 	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	c.send = chain(func(req *http.Request) (*http.Response, error) {
+		return c.client.Do(req)
+	}, append([]Middleware{TracingMiddleware(c.tracer)}, c.middlewares...))
+
+	return c
 }
 
-// Do executes an http.Request, when v is provided response body gets json unmarshalled
-// response status code is validated against basic rules
+// Do executes an http.Request bound to ctx, when v is provided response body gets json unmarshalled
+// response status code is validated against basic rules, non 2xx responses return an *APIError
+// transport errors, including context cancellation, return a *RequestError and undecodable bodies
+// a *DecodeError, all of them holding the request id, taken from request X-Request-ID header, ctx
+// or generated, and sent on every attempt
 func (c *Client) Do(ctx context.Context, req *http.Request, v interface{}) (*http.Response, error) {
-	req.WithContext(ctx)
-	resp, err := c.client.Do(req)
-	if err != nil {
-		return nil, err
+	req = req.Clone(ctx)
+	req.Header.Set(RequestIDHeader, requestID(ctx, req))
+	if c.tlsErr != nil {
+		return nil, newRequestError(ctx, req, c.tlsErr)
 	}
 
-	err = c.validateStatusCode(resp.StatusCode)
+	resp, err := c.execute(ctx, req)
 	if err != nil {
-		return resp, err
+		return nil, newRequestError(ctx, req, err)
 	}
 
 	defer func() {
 		_ = resp.Body.Close()
 	}()
 
+	if statusError(resp.StatusCode) != nil {
+		body, _ := ioutil.ReadAll(io.LimitReader(resp.Body, maxErrorBodySize))
+		resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+		return resp, newAPIError(req, resp, body)
+	}
+
 	if v != nil {
 		unMarshallErr := json.NewDecoder(resp.Body).Decode(v)
 		if unMarshallErr != nil {
-			return resp, unMarshallErr
+			return resp, &DecodeError{
+				Method:     req.Method,
+				URL:        req.URL.String(),
+				RequestID:  req.Header.Get(RequestIDHeader),
+				StatusCode: resp.StatusCode,
+				Err:        unMarshallErr,
+			}
 		}
 	}
 
 	return resp, nil
 }
 
+// newRequestError wraps transport error with request metadata, context errors take precedence
+func newRequestError(ctx context.Context, req *http.Request, err error) *RequestError {
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		err = ctxErr
+	}
+
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		err = urlErr.Err
+	}
+
+	return &RequestError{
+		Method:    req.Method,
+		URL:       req.URL.String(),
+		RequestID: req.Header.Get(RequestIDHeader),
+		Err:       err,
+	}
+}
+
+// execute sends request, failed attempts are retried following retry policy
+func (c *Client) execute(ctx context.Context, req *http.Request) (*http.Response, error) {
+	if !c.retry.allows(req) {
+		return c.send(withAttempt(req, 1))
+	}
+
+	for attempt := 1; ; attempt++ {
+		r, err := rewind(req)
+		if err != nil {
+			return nil, err
+		}
+
+		resp, err := c.send(withAttempt(r, attempt))
+		if attempt >= c.retry.MaxAttempts || !c.retry.retryable(resp, err) {
+			return resp, err
+		}
+
+		wait := c.retry.backoff(attempt, resp)
+		discard(resp)
+
+		t := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return nil, ctx.Err()
+		case <-t.C:
+		}
+	}
+}
+
+// attemptKey holds request attempt number on request context
+type attemptKey struct{}
+
+// withAttempt binds attempt number to request context and X-Request-Attempt header
+func withAttempt(req *http.Request, attempt int) *http.Request {
+	r := req.Clone(context.WithValue(req.Context(), attemptKey{}, attempt))
+	r.Header.Set(AttemptHeader, strconv.Itoa(attempt))
+
+	return r
+}
+
+// Attempt returns request attempt number, starting at 1, as seen by middlewares, 0 outside Client.Do
+func Attempt(ctx context.Context) int {
+	n, _ := ctx.Value(attemptKey{}).(int)
+
+	return n
+}
+
 // CreateRequest creates an http API request, applies json encoding to body
 func (c *Client) CreateRequest(method, url string, body interface{}) (*http.Request, error) {
 	uri, err := c.baseURL.Parse(url)
@@ -100,26 +227,9 @@ func (c *Client) CreateRequest(method, url string, body interface{}) (*http.Requ
 		req.Header.Set("Content-Type", jsonContentType)
 	}
 
-	return req, nil
-}
-
-// validateStatusCode validates common status codes
-func (c *Client) validateStatusCode(statusCode int) error {
-	if http.StatusOK <= statusCode && statusCode < http.StatusMultipleChoices {
-		return nil
+	if c.userAgent != "" {
+		req.Header.Set("User-Agent", c.userAgent)
 	}
 
-	if statusCode == http.StatusNotFound {
-		return ErrContentNotFound
-	}
-
-	if statusCode == http.StatusBadRequest {
-		return ErrBadRequest
-	}
-
-	if statusCode == http.StatusForbidden {
-		return ErrNotAuthorized
-	}
-
-	return ErrInternalServer
+	return req, nil
 }
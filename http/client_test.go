@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"net/http"
@@ -105,8 +106,14 @@ func TestClient_DoResponseStatusCodesWithErrorDetection(t *testing.T) {
 		error      error
 	}{
 		{statusCode: 200, error: nil},
+		{statusCode: 400, error: ErrBadRequest},
+		{statusCode: 401, error: ErrUnauthorized},
+		{statusCode: 403, error: ErrNotAuthorized},
 		{statusCode: 404, error: ErrContentNotFound},
+		{statusCode: 409, error: ErrConflict},
+		{statusCode: 429, error: ErrTooManyRequests},
 		{statusCode: 500, error: ErrInternalServer},
+		{statusCode: 503, error: ErrServiceUnavailable},
 	}
 
 	c := NewClient()
@@ -117,7 +124,7 @@ func TestClient_DoResponseStatusCodesWithErrorDetection(t *testing.T) {
 	for _, test := range tests {
 		c.client.Transport = &fakeTransport{statusCode: test.statusCode}
 		_, err = c.Do(context.Background(), req, nil)
-		if err != test.error {
+		if !errors.Is(err, test.error) {
 			t.Fatalf("unexpected error executing request, error %v", err)
 		}
 	}
@@ -126,12 +133,18 @@ func TestClient_DoResponseStatusCodesWithErrorDetection(t *testing.T) {
 type fakeTransport struct {
 	statusCode int
 	body       []byte
+	header     http.Header
 }
 
 func (f *fakeTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	header := f.header
+	if header == nil {
+		header = make(http.Header)
+	}
+
 	return &http.Response{
 		StatusCode: f.statusCode,
 		Body:       ioutil.NopCloser(bytes.NewBuffer(f.body)),
-		Header:     make(http.Header),
+		Header:     header,
 	}, nil
 }
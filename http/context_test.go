@@ -0,0 +1,139 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestClient_DoReturnsDeadlineExceededOnSlowServer(t *testing.T) {
+	srv := newSlowServer(time.Second)
+	defer srv.Close()
+
+	c := newServerClient(t, srv)
+	req, err := c.CreateRequest(http.MethodGet, "v1/organisation/accounts", nil)
+	if err != nil {
+		t.Fatalf("unexpected error creating request, error %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*50)
+	defer cancel()
+
+	start := time.Now()
+	_, err = c.Do(ctx, req, nil)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("unexpected error type, expected deadline exceeded got %v", err)
+	}
+
+	if elapsed := time.Since(start); elapsed > time.Millisecond*500 {
+		t.Errorf("request not bound to context deadline, took %v", elapsed)
+	}
+
+	var reqErr *RequestError
+	if !errors.As(err, &reqErr) {
+		t.Fatalf("error is not a request error, got %T", err)
+	}
+
+	if got, want := reqErr.Method, http.MethodGet; got != want {
+		t.Errorf("method does not match, expected %s got %s", want, got)
+	}
+
+	if got, want := reqErr.URL, srv.URL+"/v1/organisation/accounts"; got != want {
+		t.Errorf("url does not match, expected %s got %s", want, got)
+	}
+}
+
+func TestClient_DoReturnsCanceledOnContextCancellation(t *testing.T) {
+	srv := newSlowServer(time.Second)
+	defer srv.Close()
+
+	c := newServerClient(t, srv)
+	req, err := c.CreateRequest(http.MethodGet, "v1/organisation/accounts", nil)
+	if err != nil {
+		t.Fatalf("unexpected error creating request, error %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		time.Sleep(time.Millisecond * 50)
+		cancel()
+	}()
+
+	_, err = c.Do(ctx, req, nil)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("unexpected error type, expected canceled got %v", err)
+	}
+}
+
+func TestClient_DoCompletesWithinContextDeadline(t *testing.T) {
+	srv := newSlowServer(time.Millisecond * 10)
+	defer srv.Close()
+
+	c := newServerClient(t, srv)
+	req, err := c.CreateRequest(http.MethodGet, "v1/organisation/accounts", nil)
+	if err != nil {
+		t.Fatalf("unexpected error creating request, error %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	v := make(map[string]string)
+	_, err = c.Do(ctx, req, &v)
+	if err != nil {
+		t.Fatalf("unexpected error executing request, error %v", err)
+	}
+
+	if got, want := v["foo"], "bar"; got != want {
+		t.Errorf("unexpected response content, expected %s got %s", want, got)
+	}
+}
+
+func TestClient_DoWrapsTransportErrorsWithRequestMetadata(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	u, _ := url.Parse(srv.URL)
+	srv.Close()
+
+	c := NewClientWithUrl(u)
+	req, err := c.CreateRequest(http.MethodDelete, "foo", nil)
+	if err != nil {
+		t.Fatalf("unexpected error creating request, error %v", err)
+	}
+
+	_, err = c.Do(context.Background(), req, nil)
+	var reqErr *RequestError
+	if !errors.As(err, &reqErr) {
+		t.Fatalf("error is not a request error, got %v", err)
+	}
+
+	if got, want := reqErr.Method, http.MethodDelete; got != want {
+		t.Errorf("method does not match, expected %s got %s", want, got)
+	}
+}
+
+// newSlowServer replies after delay unless request context is done first
+func newSlowServer(delay time.Duration) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-time.After(delay):
+		}
+
+		w.Header().Set("Content-Type", jsonContentType)
+		_, _ = w.Write([]byte(`{"foo": "bar"}`))
+	}))
+}
+
+func newServerClient(t *testing.T, srv *httptest.Server) *Client {
+	u, err := url.Parse(srv.URL)
+	if err != nil {
+		t.Fatalf("unexpected error parsing url, error %v", err)
+	}
+
+	return NewClientWithUrl(u)
+}
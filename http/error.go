@@ -0,0 +1,164 @@
+package http
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// APIError describes a non 2xx api response, it matches status code sentinel errors through errors.Is,
+// sentinels are never returned bare so they cannot be compared with ==
+type APIError struct {
+	StatusCode int
+	Method     string
+	URL        string
+	RequestID  string
+	Errors     []*ErrorObject
+	Body       []byte
+	Header     http.Header
+}
+
+// RequestError wraps transport errors, as context cancellation or deadline, with request metadata
+type RequestError struct {
+	Method    string
+	URL       string
+	RequestID string
+	Err       error
+}
+
+// Error describes request and wrapped error
+func (e *RequestError) Error() string {
+	return fmt.Sprintf("%s %s: %v", e.Method, e.URL, e.Err)
+}
+
+// Unwrap returns wrapped error
+func (e *RequestError) Unwrap() error {
+	return e.Err
+}
+
+// DecodeError wraps response body decoding errors with request metadata
+type DecodeError struct {
+	Method     string
+	URL        string
+	RequestID  string
+	StatusCode int
+	Err        error
+}
+
+// Error describes request, status code and decoding error
+func (e *DecodeError) Error() string {
+	return fmt.Sprintf("%s %s: %d, undecodable response body: %v", e.Method, e.URL, e.StatusCode, e.Err)
+}
+
+// Unwrap returns decoding error
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+// ErrorObject defines a JSON:API error object, account api error_message and error_code
+// payloads are mapped to Detail and Code
+type ErrorObject struct {
+	ID     string `json:"id,omitempty"`
+	Status string `json:"status,omitempty"`
+	Code   string `json:"code,omitempty"`
+	Title  string `json:"title,omitempty"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// errorDocument holds both JSON:API errors and account api error payload
+type errorDocument struct {
+	Errors       []*ErrorObject `json:"errors"`
+	ErrorMessage string         `json:"error_message"`
+	ErrorCode    string         `json:"error_code"`
+}
+
+// newAPIError builds an api error from response, body is parsed when it holds a known error payload
+func newAPIError(req *http.Request, resp *http.Response, body []byte) *APIError {
+	e := &APIError{
+		StatusCode: resp.StatusCode,
+		Body:       body,
+		Header:     resp.Header,
+	}
+
+	if req != nil {
+		e.Method = req.Method
+		e.URL = req.URL.String()
+		e.RequestID = req.Header.Get(RequestIDHeader)
+	}
+
+	doc := &errorDocument{}
+	if err := json.Unmarshal(body, doc); err != nil {
+		return e
+	}
+
+	e.Errors = doc.Errors
+	if doc.ErrorMessage != "" || doc.ErrorCode != "" {
+		e.Errors = append(e.Errors, &ErrorObject{
+			Status: fmt.Sprintf("%d", resp.StatusCode),
+			Code:   doc.ErrorCode,
+			Detail: doc.ErrorMessage,
+		})
+	}
+
+	return e
+}
+
+// Error describes request, status code and server error details
+func (e *APIError) Error() string {
+	msg := fmt.Sprintf("%s %s: %d %s", e.Method, e.URL, e.StatusCode, http.StatusText(e.StatusCode))
+	details := e.details()
+	if details == "" {
+		return msg
+	}
+
+	return fmt.Sprintf("%s, %s", msg, details)
+}
+
+// Unwrap returns status code sentinel error
+func (e *APIError) Unwrap() error {
+	return statusError(e.StatusCode)
+}
+
+// details joins error object details, falling back to titles and codes
+func (e *APIError) details() string {
+	var parts []string
+	for _, o := range e.Errors {
+		switch {
+		case o.Detail != "":
+			parts = append(parts, o.Detail)
+		case o.Title != "":
+			parts = append(parts, o.Title)
+		case o.Code != "":
+			parts = append(parts, o.Code)
+		}
+	}
+
+	return strings.Join(parts, "; ")
+}
+
+// statusError translates status code to sentinel errors, nil on 2xx status codes
+func statusError(statusCode int) error {
+	if http.StatusOK <= statusCode && statusCode < http.StatusMultipleChoices {
+		return nil
+	}
+
+	switch statusCode {
+	case http.StatusNotFound:
+		return ErrContentNotFound
+	case http.StatusBadRequest:
+		return ErrBadRequest
+	case http.StatusUnauthorized:
+		return ErrUnauthorized
+	case http.StatusForbidden:
+		return ErrNotAuthorized
+	case http.StatusConflict:
+		return ErrConflict
+	case http.StatusTooManyRequests:
+		return ErrTooManyRequests
+	case http.StatusServiceUnavailable:
+		return ErrServiceUnavailable
+	}
+
+	return ErrInternalServer
+}
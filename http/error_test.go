@@ -0,0 +1,113 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"testing"
+)
+
+func TestClient_DoReturnsAPIErrorWithParsedAccountAPIErrorBody(t *testing.T) {
+	raw := []byte(`{"error_message": "record 7ad2 does not exist", "error_code": "not_found"}`)
+	header := make(http.Header)
+	header.Set("X-Fake", "fake")
+	c := NewClient()
+	c.client.Transport = &fakeTransport{statusCode: http.StatusNotFound, body: raw, header: header}
+
+	req, err := c.CreateRequest(http.MethodGet, "foo", nil)
+	if err != nil {
+		t.Fatalf("unexpected error creating request, error %v", err)
+	}
+
+	resp, err := c.Do(context.Background(), req, nil)
+	if !errors.Is(err, ErrContentNotFound) {
+		t.Fatalf("unexpected error type, expected content not found got %v", err)
+	}
+
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("error is not an api error, got %T", err)
+	}
+
+	if got, want := apiErr.StatusCode, http.StatusNotFound; got != want {
+		t.Errorf("status code does not match, expected %d got %d", want, got)
+	}
+
+	if got, want := apiErr.Method, http.MethodGet; got != want {
+		t.Errorf("method does not match, expected %s got %s", want, got)
+	}
+
+	if got, want := apiErr.URL, defaultBaseURL+"foo"; got != want {
+		t.Errorf("url does not match, expected %s got %s", want, got)
+	}
+
+	if got, want := string(apiErr.Body), string(raw); got != want {
+		t.Errorf("body does not match, expected %s got %s", want, got)
+	}
+
+	if got, want := apiErr.Header.Get("X-Fake"), "fake"; got != want {
+		t.Errorf("header does not match, expected %s got %s", want, got)
+	}
+
+	if got, want := len(apiErr.Errors), 1; got != want {
+		t.Fatalf("unexpected error objects size, expected %d got %d", want, got)
+	}
+
+	if got, want := apiErr.Errors[0].Detail, "record 7ad2 does not exist"; got != want {
+		t.Errorf("error detail does not match, expected %s got %s", want, got)
+	}
+
+	if got, want := apiErr.Errors[0].Code, "not_found"; got != want {
+		t.Errorf("error code does not match, expected %s got %s", want, got)
+	}
+
+	rawBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("unexpected error reading response body, error %v", err)
+	}
+
+	if got, want := string(rawBody), string(raw); got != want {
+		t.Errorf("response body does not match, expected %s got %s", want, got)
+	}
+}
+
+func TestAPIError_ParsesJSONAPIErrorObjects(t *testing.T) {
+	raw := []byte(`{"errors": [{"status": "400", "code": "validation", "title": "invalid bank id"}, {"detail": "invalid bic"}]}`)
+	req, err := http.NewRequest(http.MethodPost, "http://fake/v1/organisation/accounts", nil)
+	if err != nil {
+		t.Fatalf("unexpected error creating request, error %v", err)
+	}
+
+	e := newAPIError(req, &http.Response{StatusCode: http.StatusBadRequest}, raw)
+
+	if got, want := len(e.Errors), 2; got != want {
+		t.Fatalf("unexpected error objects size, expected %d got %d", want, got)
+	}
+
+	if !errors.Is(e, ErrBadRequest) {
+		t.Errorf("api error does not match bad request sentinel")
+	}
+
+	want := "POST http://fake/v1/organisation/accounts: 400 Bad Request, invalid bank id; invalid bic"
+	if got := e.Error(); got != want {
+		t.Errorf("error message does not match, expected %s got %s", want, got)
+	}
+}
+
+func TestAPIError_KeepsRawBodyOnNonJSONPayload(t *testing.T) {
+	raw := []byte("<html>bad gateway</html>")
+	e := newAPIError(nil, &http.Response{StatusCode: http.StatusBadGateway}, raw)
+
+	if len(e.Errors) != 0 {
+		t.Errorf("unexpected error objects, got %d", len(e.Errors))
+	}
+
+	if got, want := string(e.Body), string(raw); got != want {
+		t.Errorf("body does not match, expected %s got %s", want, got)
+	}
+
+	if !errors.Is(e, ErrInternalServer) {
+		t.Errorf("api error does not match internal server sentinel")
+	}
+}
@@ -0,0 +1,247 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/marcosQuesada/finn/internal/redact"
+)
+
+// maxLogBodySize bounds buffered response bodies, they are redacted as a whole json document
+const maxLogBodySize = 1 << 20
+
+// DefaultLogRedaction masks account personal identifiable information on captured bodies
+var DefaultLogRedaction = []string{
+	"data.attributes.private_identification",
+	"data.attributes.organisation_identification.actors.birth_date",
+	"data.attributes.organisation_identification.actors.name",
+	"data.attributes.iban",
+	"data.attributes.account_number",
+	"data.attributes.name",
+	"data.attributes.alternative_names",
+}
+
+// LogRecord describes a request attempt, sizes are -1 when unknown
+type LogRecord struct {
+	Time         time.Time     `json:"time"`
+	Method       string        `json:"method"`
+	Path         string        `json:"path"`
+	Status       int           `json:"status,omitempty"`
+	Latency      time.Duration `json:"latency"`
+	Attempt      int           `json:"attempt"`
+	RequestID    string        `json:"request_id,omitempty"`
+	RequestSize  int64         `json:"request_size"`
+	ResponseSize int64         `json:"response_size"`
+	RequestBody  string        `json:"request_body,omitempty"`
+	ResponseBody string        `json:"response_body,omitempty"`
+	Error        string        `json:"error,omitempty"`
+}
+
+// Logger receives a record per request attempt
+type Logger interface {
+	Log(r *LogRecord)
+}
+
+// LoggerFunc adapts a function to Logger
+type LoggerFunc func(r *LogRecord)
+
+// Log calls f
+func (f LoggerFunc) Log(r *LogRecord) {
+	f(r)
+}
+
+// JSONLogger writes records as JSON lines
+type JSONLogger struct {
+	mu  sync.Mutex
+	enc *json.Encoder
+}
+
+// NewJSONLogger instantiates a JSON lines logger
+func NewJSONLogger(w io.Writer) *JSONLogger {
+	return &JSONLogger{enc: json.NewEncoder(w)}
+}
+
+// Log writes record line
+func (j *JSONLogger) Log(r *LogRecord) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	_ = j.enc.Encode(r)
+}
+
+// LogOption configures logging middleware
+type LogOption func(*logConfig)
+
+// logConfig holds logging middleware options
+type logConfig struct {
+	capture int
+	paths   []string
+}
+
+// WithBodyCapture logs request and response bodies up to max bytes, bodies are redacted before being
+// truncated, response bodies longer than 1MiB are not captured
+func WithBodyCapture(max int) LogOption {
+	return func(c *logConfig) {
+		c.capture = max
+	}
+}
+
+// WithLogRedaction replaces redacted json body paths, as data.attributes.iban
+func WithLogRedaction(paths ...string) LogOption {
+	return func(c *logConfig) {
+		c.paths = paths
+	}
+}
+
+// WithLogger logs each request attempt through l
+func WithLogger(l Logger, opts ...LogOption) Option {
+	return WithMiddleware(LoggingMiddleware(l, opts...))
+}
+
+// LoggingMiddleware emits a record per request attempt, once response body is closed, so that response
+// size counts the bytes read by the consumer, bodies are only buffered when captured, up to maxLogBodySize
+func LoggingMiddleware(l Logger, opts ...LogOption) Middleware {
+	cfg := &logConfig{paths: DefaultLogRedaction}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	return func(next RoundTripFunc) RoundTripFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			rec := &LogRecord{
+				Time:         time.Now(),
+				Method:       req.Method,
+				Path:         req.URL.Path,
+				Attempt:      Attempt(req.Context()),
+				RequestID:    req.Header.Get(RequestIDHeader),
+				RequestSize:  req.ContentLength,
+				ResponseSize: -1,
+			}
+
+			if cfg.capture > 0 {
+				rec.RequestBody = cfg.body(peekRequestBody(req))
+			}
+
+			resp, err := next(req)
+			rec.Latency = time.Since(rec.Time)
+			if err != nil {
+				rec.Error = err.Error()
+			}
+
+			if resp == nil || resp.Body == nil {
+				l.Log(rec)
+				return resp, err
+			}
+
+			rec.Status = resp.StatusCode
+			if cfg.capture > 0 {
+				rec.ResponseBody = cfg.body(peekResponseBody(resp))
+			}
+
+			resp.Body = &countingBody{
+				ReadCloser: resp.Body,
+				done: func(n int64) {
+					rec.ResponseSize = n
+					l.Log(rec)
+				},
+			}
+
+			return resp, err
+		}
+	}
+}
+
+// body redacts and truncates captured body
+func (c *logConfig) body(b []byte) string {
+	b = redact.JSON(b, c.paths)
+	if len(b) > c.capture {
+		b = b[:c.capture]
+	}
+
+	return string(b)
+}
+
+// peekRequestBody reads request body without consuming it
+func peekRequestBody(req *http.Request) []byte {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil
+	}
+
+	if req.GetBody != nil {
+		r, err := req.GetBody()
+		if err != nil {
+			return nil
+		}
+		b, _ := ioutil.ReadAll(r)
+		_ = r.Close()
+
+		return b
+	}
+
+	b, err := ioutil.ReadAll(req.Body)
+	_ = req.Body.Close()
+	req.Body = ioutil.NopCloser(bytes.NewReader(b))
+	if err != nil {
+		return nil
+	}
+
+	return b
+}
+
+// peekResponseBody reads up to maxLogBodySize response body bytes restoring them for further reads,
+// longer bodies are not returned as they cannot be redacted
+func peekResponseBody(resp *http.Response) []byte {
+	b, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxLogBodySize+1))
+	resp.Body = &prefixedBody{
+		Reader:     io.MultiReader(bytes.NewReader(b), resp.Body),
+		ReadCloser: resp.Body,
+	}
+
+	if err != nil || len(b) > maxLogBodySize {
+		return nil
+	}
+
+	return b
+}
+
+// prefixedBody reads already buffered bytes before remaining body
+type prefixedBody struct {
+	io.Reader
+	io.ReadCloser
+}
+
+// Read reads buffered bytes first
+func (b *prefixedBody) Read(p []byte) (int, error) {
+	return b.Reader.Read(p)
+}
+
+// countingBody counts read bytes, done is called once body is closed
+type countingBody struct {
+	io.ReadCloser
+	n    int64
+	once sync.Once
+	done func(n int64)
+}
+
+// Read counts read bytes
+func (b *countingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.n += int64(n)
+
+	return n, err
+}
+
+// Close closes body and reports read bytes
+func (b *countingBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(func() {
+		b.done(b.n)
+	})
+
+	return err
+}
@@ -0,0 +1,202 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+const piiAccount = `{"data": {"id": "fakeID", "attributes": {"country": "GB", "iban": "GB16NWBK40030041426819", "account_number": "41426819", "name": ["Jane Doe"], "private_identification": {"birth_date": "2000-01-01", "identification": "fakeID"}}}}`
+
+func TestLoggingMiddleware_RecordsEachAttempt(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte(piiAccount))
+	}))
+	defer srv.Close()
+
+	var records []*LogRecord
+	u, _ := url.Parse(srv.URL)
+	p := &RetryPolicy{MaxAttempts: 2, BaseBackoff: time.Millisecond, RetryableStatusCodes: []int{http.StatusServiceUnavailable}}
+	c := NewClientWithRetryPolicy(u, p, WithLogger(LoggerFunc(func(r *LogRecord) {
+		records = append(records, r)
+	})))
+
+	req, _ := c.CreateRequest(http.MethodGet, "v1/organisation/accounts/fakeID", nil)
+	req.Header.Set("X-Request-ID", "fakeRequestID")
+	v := map[string]interface{}{}
+	if _, err := c.Do(context.Background(), req, &v); err != nil {
+		t.Fatalf("unexpected error on request, error %v", err)
+	}
+
+	if len(records) != 2 {
+		t.Fatalf("records do not match, expected 2 got %d", len(records))
+	}
+
+	for i, rec := range records {
+		if rec.Attempt != i+1 || rec.Method != http.MethodGet || rec.Path != "/v1/organisation/accounts/fakeID" || rec.RequestID != "fakeRequestID" {
+			t.Errorf("unexpected record %d, got %+v", i, rec)
+		}
+
+		if rec.RequestBody != "" || rec.ResponseBody != "" {
+			t.Errorf("bodies captured without body capture option, got %+v", rec)
+		}
+	}
+
+	if records[0].Status != http.StatusServiceUnavailable || records[1].Status != http.StatusOK {
+		t.Errorf("unexpected statuses, got %d and %d", records[0].Status, records[1].Status)
+	}
+
+	if got, want := records[1].ResponseSize, int64(len(piiAccount)); got != want {
+		t.Errorf("response size does not match, expected %d got %d", want, got)
+	}
+
+	if v["data"] == nil {
+		t.Error("response body not restored after logging")
+	}
+}
+
+func TestLoggingMiddleware_RedactsCapturedBodies(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(piiAccount))
+	}))
+	defer srv.Close()
+
+	out := &bytes.Buffer{}
+	u, _ := url.Parse(srv.URL)
+	c := NewClientWithUrl(u, WithLogger(NewJSONLogger(out), WithBodyCapture(4096)))
+
+	body := map[string]interface{}{}
+	_ = json.Unmarshal([]byte(piiAccount), &body)
+	req, _ := c.CreateRequest(http.MethodPost, "v1/organisation/accounts", body)
+	if _, err := c.Do(context.Background(), req, nil); err != nil {
+		t.Fatalf("unexpected error on request, error %v", err)
+	}
+
+	rec := &LogRecord{}
+	if err := json.Unmarshal(out.Bytes(), rec); err != nil {
+		t.Fatalf("unexpected error decoding record %s, error %v", out.String(), err)
+	}
+
+	if rec.Status != http.StatusCreated || rec.RequestSize != req.ContentLength || rec.Attempt != 1 {
+		t.Errorf("unexpected record, got %+v", rec)
+	}
+
+	for name, captured := range map[string]string{"request": rec.RequestBody, "response": rec.ResponseBody} {
+		for _, pii := range []string{"GB16NWBK40030041426819", "41426819", "Jane Doe", "2000-01-01"} {
+			if strings.Contains(captured, pii) {
+				t.Errorf("%s body leaks %s, got %s", name, pii, captured)
+			}
+		}
+
+		if !strings.Contains(captured, `"country":"GB"`) {
+			t.Errorf("%s body lost not redacted fields, got %s", name, captured)
+		}
+	}
+}
+
+func TestLoggingMiddleware_TruncatesCapturedBodies(t *testing.T) {
+	cfg := &logConfig{capture: 10, paths: DefaultLogRedaction}
+	if got := cfg.body([]byte(piiAccount)); len(got) != 10 || strings.Contains(got, "GB16") {
+		t.Errorf("unexpected captured body, got %s", got)
+	}
+}
+
+// trackedBody counts bytes read from the underlying body
+type trackedBody struct {
+	io.Reader
+	read int
+}
+
+func (b *trackedBody) Read(p []byte) (int, error) {
+	n, err := b.Reader.Read(p)
+	b.read += n
+
+	return n, err
+}
+
+func (b *trackedBody) Close() error {
+	return nil
+}
+
+func TestLoggingMiddleware_CountsResponseBytesWithoutBuffering(t *testing.T) {
+	body := &trackedBody{Reader: bytes.NewReader(make([]byte, 4<<20))}
+	var records []*LogRecord
+	send := LoggingMiddleware(LoggerFunc(func(r *LogRecord) {
+		records = append(records, r)
+	}))(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusOK, Body: body}, nil
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/organisation/accounts", nil)
+	resp, err := send(req)
+	if err != nil {
+		t.Fatalf("unexpected error on request, error %v", err)
+	}
+
+	if body.read != 0 || len(records) != 0 {
+		t.Fatalf("response consumed before being read, read %d bytes", body.read)
+	}
+
+	if _, err := io.CopyN(ioutil.Discard, resp.Body, 10); err != nil {
+		t.Fatalf("unexpected error reading body, error %v", err)
+	}
+	_ = resp.Body.Close()
+	_ = resp.Body.Close()
+
+	if len(records) != 1 {
+		t.Fatalf("unexpected records, expected 1 got %d", len(records))
+	}
+
+	if got := records[0].ResponseSize; got != 10 {
+		t.Errorf("unexpected response size, expected 10 got %d", got)
+	}
+}
+
+func TestLoggingMiddleware_BoundsCapturedResponseBodies(t *testing.T) {
+	raw := bytes.Repeat([]byte("a"), 2<<20)
+	body := &trackedBody{Reader: bytes.NewReader(raw)}
+	var rec *LogRecord
+	send := LoggingMiddleware(LoggerFunc(func(r *LogRecord) {
+		rec = r
+	}), WithBodyCapture(64))(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusOK, Body: body}, nil
+	})
+
+	resp, err := send(httptest.NewRequest(http.MethodGet, "/v1/organisation/accounts", nil))
+	if err != nil {
+		t.Fatalf("unexpected error on request, error %v", err)
+	}
+
+	if body.read > maxLogBodySize+1 {
+		t.Errorf("unbounded body buffering, read %d bytes", body.read)
+	}
+
+	got, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("unexpected error reading body, error %v", err)
+	}
+	_ = resp.Body.Close()
+
+	if !bytes.Equal(got, raw) {
+		t.Errorf("response body altered, got %d bytes", len(got))
+	}
+
+	if rec == nil || rec.ResponseBody != "" || rec.ResponseSize != int64(len(raw)) {
+		t.Errorf("unexpected record, got %+v", rec)
+	}
+}
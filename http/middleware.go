@@ -0,0 +1,24 @@
+package http
+
+import "net/http"
+
+// RoundTripFunc executes a single http request attempt
+type RoundTripFunc func(req *http.Request) (*http.Response, error)
+
+// RoundTrip implements http.RoundTripper
+func (f RoundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// Middleware wraps request execution, enabling stackable layers as auth, logging or tracing,
+// middlewares run on each attempt in registration order, first one is the outermost layer
+type Middleware func(next RoundTripFunc) RoundTripFunc
+
+// chain wraps final round trip with middlewares
+func chain(final RoundTripFunc, mws []Middleware) RoundTripFunc {
+	for i := len(mws) - 1; i >= 0; i-- {
+		final = mws[i](final)
+	}
+
+	return final
+}
@@ -0,0 +1,66 @@
+package http
+
+import (
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// Option configures Client
+type Option func(*Client)
+
+// WithHTTPClient replaces underlying http client, tls configuration set by WithTLSConfig is applied to it
+func WithHTTPClient(hc *http.Client) Option {
+	return func(c *Client) {
+		c.client = hc
+		c.applyTLS()
+	}
+}
+
+// WithTransport sets underlying http client transport, shared http clients are not mutated,
+// tls configuration set by WithTLSConfig is applied to it
+func WithTransport(rt http.RoundTripper) Option {
+	return func(c *Client) {
+		hc := *c.client
+		hc.Transport = rt
+		c.client = &hc
+		c.applyTLS()
+	}
+}
+
+// WithTimeout sets underlying http client timeout, context deadlines are the preferred way to scope requests
+func WithTimeout(d time.Duration) Option {
+	return func(c *Client) {
+		hc := *c.client
+		hc.Timeout = d
+		c.client = &hc
+	}
+}
+
+// WithMiddleware appends middlewares to request execution chain
+func WithMiddleware(mws ...Middleware) Option {
+	return func(c *Client) {
+		c.middlewares = append(c.middlewares, mws...)
+	}
+}
+
+// WithUserAgent sets User-Agent header on created requests
+func WithUserAgent(ua string) Option {
+	return func(c *Client) {
+		c.userAgent = ua
+	}
+}
+
+// WithRetryPolicy enables failed request retries
+func WithRetryPolicy(p *RetryPolicy) Option {
+	return func(c *Client) {
+		c.retry = p
+	}
+}
+
+// WithBaseURL overrides default base url
+func WithBaseURL(u *url.URL) Option {
+	return func(c *Client) {
+		c.baseURL = u
+	}
+}
@@ -0,0 +1,133 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestClient_DoRunsMiddlewaresInRegistrationOrder(t *testing.T) {
+	var calls []string
+	tracker := func(name string) Middleware {
+		return func(next RoundTripFunc) RoundTripFunc {
+			return func(req *http.Request) (*http.Response, error) {
+				calls = append(calls, name+":before")
+				resp, err := next(req)
+				calls = append(calls, name+":after")
+				return resp, err
+			}
+		}
+	}
+
+	c := NewClient(
+		WithTransport(&fakeTransport{statusCode: http.StatusOK}),
+		WithMiddleware(tracker("first"), tracker("second")),
+	)
+
+	req, err := c.CreateRequest(http.MethodGet, "foo", nil)
+	if err != nil {
+		t.Fatalf("unexpected error creating request, error %v", err)
+	}
+
+	_, err = c.Do(context.Background(), req, nil)
+	if err != nil {
+		t.Fatalf("unexpected error executing request, error %v", err)
+	}
+
+	want := []string{"first:before", "second:before", "second:after", "first:after"}
+	if !reflect.DeepEqual(calls, want) {
+		t.Errorf("unexpected middleware order, expected %v got %v", want, calls)
+	}
+}
+
+func TestClient_DoMiddlewareCanDecorateRequests(t *testing.T) {
+	tr := &headerTransport{}
+	auth := func(next RoundTripFunc) RoundTripFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			req.Header.Set("Authorization", "Bearer fakeToken")
+			return next(req)
+		}
+	}
+
+	c := NewClient(WithTransport(tr), WithMiddleware(auth), WithUserAgent("finn/test"))
+	req, err := c.CreateRequest(http.MethodGet, "foo", nil)
+	if err != nil {
+		t.Fatalf("unexpected error creating request, error %v", err)
+	}
+
+	_, err = c.Do(context.Background(), req, nil)
+	if err != nil {
+		t.Fatalf("unexpected error executing request, error %v", err)
+	}
+
+	if got, want := tr.header.Get("Authorization"), "Bearer fakeToken"; got != want {
+		t.Errorf("authorization header does not match, expected %s got %s", want, got)
+	}
+
+	if got, want := tr.header.Get("User-Agent"), "finn/test"; got != want {
+		t.Errorf("user agent header does not match, expected %s got %s", want, got)
+	}
+}
+
+func TestClient_DoRunsMiddlewaresOnEachRetryAttempt(t *testing.T) {
+	attempts := 0
+	counter := func(next RoundTripFunc) RoundTripFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			attempts++
+			return next(req)
+		}
+	}
+
+	p := DefaultRetryPolicy()
+	p.BaseBackoff = time.Millisecond
+	c := NewClient(
+		WithTransport(&sequenceTransport{statusCodes: []int{503, 200}}),
+		WithRetryPolicy(p),
+		WithMiddleware(counter),
+	)
+
+	req, err := c.CreateRequest(http.MethodGet, "foo", nil)
+	if err != nil {
+		t.Fatalf("unexpected error creating request, error %v", err)
+	}
+
+	_, err = c.Do(context.Background(), req, nil)
+	if err != nil {
+		t.Fatalf("unexpected error executing request, error %v", err)
+	}
+
+	if got, want := attempts, 2; got != want {
+		t.Errorf("unexpected middleware calls, expected %d got %d", want, got)
+	}
+}
+
+func TestNewClient_OptionsDoNotMutateSharedHTTPClient(t *testing.T) {
+	hc := &http.Client{}
+	u, _ := url.Parse("http://fake/")
+	c := NewClientWithUrl(u, WithHTTPClient(hc), WithTimeout(time.Second), WithTransport(&fakeTransport{}))
+
+	if hc.Timeout != 0 || hc.Transport != nil {
+		t.Error("shared http client has been mutated")
+	}
+
+	if got, want := c.client.Timeout, time.Second; got != want {
+		t.Errorf("timeout does not match, expected %v got %v", want, got)
+	}
+
+	if got, want := c.baseURL.String(), "http://fake/"; got != want {
+		t.Errorf("base url does not match, expected %s got %s", want, got)
+	}
+}
+
+type headerTransport struct {
+	header http.Header
+}
+
+func (h *headerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	h.header = req.Header.Clone()
+
+	return (&fakeTransport{statusCode: http.StatusOK}).RoundTrip(req)
+}
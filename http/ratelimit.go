@@ -0,0 +1,337 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrRateLimited happens when rate limiter wait would not complete before context deadline
+var ErrRateLimited = errors.New("rate limited")
+
+const defaultBudgetKey = "*"
+
+// minRateFactor bounds how much 429 responses can slow a budget down
+const minRateFactor = 0.05
+
+// recoverFactor is the share of configured rate recovered on each successful response
+const recoverFactor = 0.05
+
+var idSegment = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// Budget defines a token bucket, Rate tokens per second are added up to Burst tokens, Burst is at
+// least 1 and a zero Rate does not limit requests, only server feedback pauses them
+type Budget struct {
+	Rate  float64
+	Burst int
+}
+
+// BucketState describes a budget bucket, LastWait and LastReason tell why last request was delayed
+type BucketState struct {
+	Key         string
+	Rate        float64
+	Burst       int
+	Tokens      float64
+	PausedUntil time.Time
+	LastWait    time.Duration
+	LastReason  string
+}
+
+// RateLimiter is a token bucket limiter, shareable across clients, requests take tokens from the
+// budget matching "METHOD route", route, METHOD or the default budget, in that order, routes are
+// request paths with uuid segments replaced by {id}. Budgets adapt to server feedback: 429 responses
+// halve budget rate, recovered on successful responses, X-RateLimit-Remaining and X-RateLimit-Reset
+// headers cap rate to server remaining quota and Retry-After or exhausted quotas pause the budget
+type RateLimiter struct {
+	mu      sync.Mutex
+	budgets map[string]Budget
+	buckets map[string]*bucket
+	now     func() time.Time
+}
+
+// bucket holds budget token bucket state
+type bucket struct {
+	budget      Budget
+	rate        float64
+	tokens      float64
+	last        time.Time
+	pausedUntil time.Time
+	pauseReason string
+	lastWait    time.Duration
+	lastReason  string
+}
+
+// RateLimiterOption configures RateLimiter
+type RateLimiterOption func(*RateLimiter)
+
+// WithBudget sets budget for a "METHOD route", route or METHOD key
+func WithBudget(key string, b Budget) RateLimiterOption {
+	return func(l *RateLimiter) {
+		l.budgets[key] = b
+	}
+}
+
+// NewRateLimiter instantiates a rate limiter, def budget applies to requests without a specific budget
+func NewRateLimiter(def Budget, opts ...RateLimiterOption) *RateLimiter {
+	l := &RateLimiter{
+		budgets: map[string]Budget{defaultBudgetKey: def},
+		buckets: make(map[string]*bucket),
+		now:     time.Now,
+	}
+
+	for _, opt := range opts {
+		opt(l)
+	}
+
+	return l
+}
+
+// WithRateLimiter throttles each request attempt through l
+func WithRateLimiter(l *RateLimiter) Option {
+	return WithMiddleware(l.Middleware())
+}
+
+// Middleware waits for a budget token before each attempt and feeds responses back to the budget
+func (l *RateLimiter) Middleware() Middleware {
+	return func(next RoundTripFunc) RoundTripFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			key := l.key(req)
+			if _, err := l.wait(req.Context(), key); err != nil {
+				return nil, err
+			}
+
+			resp, err := next(req)
+			if err == nil {
+				l.observe(key, resp)
+			}
+
+			return resp, err
+		}
+	}
+}
+
+// Wait blocks until req budget has a token, returning waited time, ErrRateLimited is returned
+// without waiting when the token would not be available before ctx deadline
+func (l *RateLimiter) Wait(ctx context.Context, req *http.Request) (time.Duration, error) {
+	return l.wait(ctx, l.key(req))
+}
+
+// Observe feeds req response back to its budget
+func (l *RateLimiter) Observe(req *http.Request, resp *http.Response) {
+	l.observe(l.key(req), resp)
+}
+
+// State returns every budget bucket state
+func (l *RateLimiter) State() []BucketState {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	states := make([]BucketState, 0, len(l.buckets))
+	for k, b := range l.buckets {
+		b.refill(now)
+		states = append(states, BucketState{
+			Key:         k,
+			Rate:        b.rate,
+			Burst:       b.budget.Burst,
+			Tokens:      b.tokens,
+			PausedUntil: b.pausedUntil,
+			LastWait:    b.lastWait,
+			LastReason:  b.lastReason,
+		})
+	}
+
+	return states
+}
+
+// wait reserves a token from key bucket and waits for it
+func (l *RateLimiter) wait(ctx context.Context, key string) (time.Duration, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	l.mu.Lock()
+	b := l.bucket(key)
+	now := l.now()
+	d, reason := b.reserve(now)
+	if deadline, ok := ctx.Deadline(); ok && d > 0 && now.Add(d).After(deadline) {
+		b.release()
+		l.mu.Unlock()
+
+		return 0, fmt.Errorf("budget %s needs %v, %s, error %w", key, d, reason, ErrRateLimited)
+	}
+	b.lastWait, b.lastReason = d, reason
+	l.mu.Unlock()
+
+	if d <= 0 {
+		return 0, nil
+	}
+
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return d, nil
+	case <-ctx.Done():
+		l.mu.Lock()
+		b.release()
+		l.mu.Unlock()
+
+		return 0, ctx.Err()
+	}
+}
+
+// observe adapts key bucket to response status code and rate limit headers
+func (l *RateLimiter) observe(key string, resp *http.Response) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	b := l.bucket(key)
+	now := l.now()
+	b.refill(now)
+
+	if resp.StatusCode == http.StatusTooManyRequests {
+		b.rate = math.Max(b.rate/2, b.budget.Rate*minRateFactor)
+		b.pause(now, 0, "429 response")
+	} else if resp.StatusCode < http.StatusBadRequest {
+		b.rate = math.Min(b.rate+b.budget.Rate*recoverFactor, b.budget.Rate)
+	}
+
+	if d, ok := retryAfter(resp.Header.Get("Retry-After"), now); ok {
+		b.pause(now, d, "Retry-After")
+	}
+
+	remaining, err := strconv.Atoi(resp.Header.Get("X-RateLimit-Remaining"))
+	if err != nil {
+		return
+	}
+
+	reset, ok := rateLimitReset(resp.Header.Get("X-RateLimit-Reset"), now)
+	if !ok {
+		return
+	}
+
+	if remaining <= 0 {
+		b.pause(now, reset, "X-RateLimit-Remaining exhausted")
+		return
+	}
+
+	if reset > 0 {
+		b.rate = math.Min(b.rate, float64(remaining)/reset.Seconds())
+	}
+}
+
+// bucket returns key bucket, creating it from its budget
+func (l *RateLimiter) bucket(key string) *bucket {
+	b, ok := l.buckets[key]
+	if !ok {
+		budget := l.budgets[key]
+		if budget.Burst < 1 {
+			budget.Burst = 1
+		}
+		b = &bucket{
+			budget: budget,
+			rate:   budget.Rate,
+			tokens: float64(budget.Burst),
+			last:   l.now(),
+		}
+		l.buckets[key] = b
+	}
+
+	return b
+}
+
+// key selects request budget key
+func (l *RateLimiter) key(req *http.Request) string {
+	r := route(req.URL.Path)
+	for _, k := range []string{req.Method + " " + r, r, req.Method} {
+		if _, ok := l.budgets[k]; ok {
+			return k
+		}
+	}
+
+	return defaultBudgetKey
+}
+
+// refill adds tokens accrued since last refill
+func (b *bucket) refill(now time.Time) {
+	if elapsed := now.Sub(b.last); elapsed > 0 {
+		b.tokens = math.Min(b.tokens+elapsed.Seconds()*b.rate, float64(b.budget.Burst))
+		b.last = now
+	}
+}
+
+// reserve takes a token returning wait until it is available and the reason of the wait
+func (b *bucket) reserve(now time.Time) (time.Duration, string) {
+	var d time.Duration
+	reason := ""
+	if b.budget.Rate > 0 {
+		b.refill(now)
+		b.tokens--
+		if b.tokens < 0 {
+			d, reason = time.Duration(-b.tokens/b.rate*float64(time.Second)), "budget exhausted"
+		}
+	}
+
+	if pause := b.pausedUntil.Sub(now); pause > d {
+		d, reason = pause, b.pauseReason
+	}
+
+	return d, reason
+}
+
+// release returns a reserved token
+func (b *bucket) release() {
+	if b.budget.Rate > 0 {
+		b.tokens++
+	}
+}
+
+// pause holds bucket for d, zero d keeps an ongoing longer pause or waits a token at current rate
+func (b *bucket) pause(now time.Time, d time.Duration, reason string) {
+	if d == 0 && b.rate > 0 {
+		d = time.Duration(float64(time.Second) / b.rate)
+	}
+
+	if until := now.Add(d); until.After(b.pausedUntil) {
+		b.pausedUntil, b.pauseReason = until, reason
+	}
+}
+
+// rateLimitReset parses X-RateLimit-Reset as epoch seconds or as delay seconds
+func rateLimitReset(v string, now time.Time) (time.Duration, bool) {
+	secs, err := strconv.ParseInt(v, 10, 64)
+	if err != nil || secs < 0 {
+		return 0, false
+	}
+
+	if secs > 1e9 {
+		d := time.Unix(secs, 0).Sub(now)
+		if d < 0 {
+			d = 0
+		}
+
+		return d, true
+	}
+
+	return time.Duration(secs) * time.Second, true
+}
+
+// route replaces uuid path segments by {id}
+func route(p string) string {
+	segments := strings.Split(p, "/")
+	for i, s := range segments {
+		if idSegment.MatchString(s) {
+			segments[i] = "{id}"
+		}
+	}
+
+	return strings.Join(segments, "/")
+}
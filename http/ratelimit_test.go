@@ -0,0 +1,180 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRateLimiter_WaitsForTokensOnceBurstIsSpent(t *testing.T) {
+	l := NewRateLimiter(Budget{Rate: 50, Burst: 2})
+	req := httptest.NewRequest(http.MethodGet, "/v1/organisation/accounts", nil)
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		if _, err := l.Wait(context.Background(), req); err != nil {
+			t.Fatalf("unexpected error waiting, error %v", err)
+		}
+	}
+
+	if elapsed := time.Since(start); elapsed < 15*time.Millisecond {
+		t.Errorf("request not delayed after burst, took %v", elapsed)
+	}
+
+	state := l.State()
+	if len(state) != 1 || state[0].Key != "*" || state[0].LastReason != "budget exhausted" || state[0].LastWait <= 0 {
+		t.Errorf("unexpected limiter state, got %+v", state)
+	}
+}
+
+func TestRateLimiter_FailsFastWhenDeadlineIsTooClose(t *testing.T) {
+	l := NewRateLimiter(Budget{Rate: 1, Burst: 1})
+	req := httptest.NewRequest(http.MethodGet, "/v1/organisation/accounts", nil)
+	if _, err := l.Wait(context.Background(), req); err != nil {
+		t.Fatalf("unexpected error waiting, error %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := l.Wait(ctx, req)
+	if !errors.Is(err, ErrRateLimited) {
+		t.Fatalf("unexpected error type, expected rate limited got %v", err)
+	}
+
+	if elapsed := time.Since(start); elapsed > 20*time.Millisecond {
+		t.Errorf("wait not skipped on close deadline, took %v", elapsed)
+	}
+}
+
+func TestRateLimiter_SelectsBudgetByMethodAndRoute(t *testing.T) {
+	l := NewRateLimiter(Budget{},
+		WithBudget("DELETE", Budget{Rate: 1, Burst: 1}),
+		WithBudget("GET /v1/organisation/accounts/{id}", Budget{Rate: 2, Burst: 1}),
+		WithBudget("/v1/organisation/accounts", Budget{Rate: 3, Burst: 1}),
+	)
+
+	tests := []struct {
+		method string
+		path   string
+		key    string
+	}{
+		{method: http.MethodDelete, path: "/v1/organisation/accounts/ad27e265-9605-4b4b-a0e5-3003ea9cc4dc", key: "DELETE"},
+		{method: http.MethodGet, path: "/v1/organisation/accounts/ad27e265-9605-4b4b-a0e5-3003ea9cc4dc", key: "GET /v1/organisation/accounts/{id}"},
+		{method: http.MethodPost, path: "/v1/organisation/accounts", key: "/v1/organisation/accounts"},
+		{method: http.MethodPatch, path: "/v1/organisation/accounts/ad27e265-9605-4b4b-a0e5-3003ea9cc4dc", key: "*"},
+	}
+
+	for _, test := range tests {
+		req := httptest.NewRequest(test.method, test.path, nil)
+		if got := l.key(req); got != test.key {
+			t.Errorf("budget key does not match on %s %s, expected %s got %s", test.method, test.path, test.key, got)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	del := httptest.NewRequest(http.MethodDelete, tests[0].path, nil)
+	get := httptest.NewRequest(http.MethodGet, "/v1/organisation/other", nil)
+	for i := 0; i < 3; i++ {
+		if _, err := l.Wait(ctx, get); err != nil {
+			t.Errorf("unexpected error on unlimited budget, error %v", err)
+		}
+	}
+
+	if _, err := l.Wait(ctx, del); err != nil {
+		t.Fatalf("unexpected error waiting, error %v", err)
+	}
+
+	if _, err := l.Wait(ctx, del); !errors.Is(err, ErrRateLimited) {
+		t.Errorf("unexpected error type, expected rate limited got %v", err)
+	}
+}
+
+func TestRateLimiter_AdaptsToServerFeedback(t *testing.T) {
+	now := time.Now()
+	l := NewRateLimiter(Budget{Rate: 10, Burst: 10})
+	l.now = func() time.Time { return now }
+	req := httptest.NewRequest(http.MethodGet, "/v1/organisation/accounts", nil)
+
+	tests := []struct {
+		name   string
+		status int
+		header map[string]string
+		rate   float64
+		paused time.Duration
+		reason string
+	}{
+		{name: "throttled", status: http.StatusTooManyRequests, rate: 5, paused: 200 * time.Millisecond, reason: "429 response"},
+		{name: "retry after", status: http.StatusTooManyRequests, header: map[string]string{"Retry-After": "3"}, rate: 2.5, paused: 3 * time.Second, reason: "Retry-After"},
+		{name: "recovered", status: http.StatusOK, rate: 3},
+		{name: "quota", status: http.StatusOK, header: map[string]string{"X-RateLimit-Remaining": "10", "X-RateLimit-Reset": "20"}, rate: 0.5},
+		{name: "exhausted", status: http.StatusOK, header: map[string]string{"X-RateLimit-Remaining": "0", "X-RateLimit-Reset": "5"}, rate: 1, paused: 5 * time.Second, reason: "X-RateLimit-Remaining exhausted"},
+	}
+
+	for _, test := range tests {
+		if b, ok := l.buckets["*"]; ok {
+			b.pausedUntil = time.Time{}
+		}
+		resp := &http.Response{StatusCode: test.status, Header: make(http.Header)}
+		for k, v := range test.header {
+			resp.Header.Set(k, v)
+		}
+		l.Observe(req, resp)
+
+		state := l.State()[0]
+		if state.Rate != test.rate {
+			t.Errorf("%s rate does not match, expected %v got %v", test.name, test.rate, state.Rate)
+		}
+
+		if got := state.PausedUntil.Sub(now); test.paused > 0 && got != test.paused {
+			t.Errorf("%s pause does not match, expected %v got %v", test.name, test.paused, got)
+		}
+
+		if test.reason == "" {
+			continue
+		}
+
+		if _, reason := l.buckets["*"].reserve(now); reason != test.reason {
+			t.Errorf("%s wait reason does not match, expected %s got %s", test.name, test.reason, reason)
+		}
+	}
+}
+
+func TestRateLimiter_SharedAcrossClients(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-RateLimit-Remaining", "0")
+		w.Header().Set("X-RateLimit-Reset", "1")
+	}))
+	defer srv.Close()
+
+	u, _ := url.Parse(srv.URL)
+	l := NewRateLimiter(Budget{Rate: 100, Burst: 10})
+	first := NewClientWithUrl(u, WithRateLimiter(l))
+	second := NewClientWithUrl(u, WithRateLimiter(l))
+
+	req, _ := first.CreateRequest(http.MethodGet, "v1/organisation/accounts", nil)
+	if _, err := first.Do(context.Background(), req, nil); err != nil {
+		t.Fatalf("unexpected error on first request, error %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	req, _ = second.CreateRequest(http.MethodGet, "v1/organisation/accounts", nil)
+	_, err := second.Do(ctx, req, nil)
+	if !errors.Is(err, ErrRateLimited) {
+		t.Fatalf("unexpected error type, expected rate limited got %v", err)
+	}
+
+	if !strings.Contains(err.Error(), "X-RateLimit-Remaining exhausted") {
+		t.Errorf("error does not explain delay, got %v", err)
+	}
+}
@@ -0,0 +1,48 @@
+package http
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/google/uuid"
+)
+
+// RequestIDHeader identifies a logical request, it is kept across retry attempts
+const RequestIDHeader = "X-Request-ID"
+
+// AttemptHeader carries request attempt number, starting at 1
+const AttemptHeader = "X-Request-Attempt"
+
+// requestIDKey holds request id on context
+type requestIDKey struct{}
+
+// ContextWithRequestID binds request id to ctx, requests done with it are sent with that id
+func ContextWithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestIDFromContext returns ctx request id, empty when ctx holds none
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+
+	return id
+}
+
+// NewRequestID generates a random request id
+func NewRequestID() string {
+	return uuid.New().String()
+}
+
+// requestID returns request id, already set request header takes precedence over ctx one,
+// a new one is generated when none is found
+func requestID(ctx context.Context, req *http.Request) string {
+	if id := req.Header.Get(RequestIDHeader); id != "" {
+		return id
+	}
+
+	if id := RequestIDFromContext(ctx); id != "" {
+		return id
+	}
+
+	return NewRequestID()
+}
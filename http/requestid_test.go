@@ -0,0 +1,131 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestClient_RequestIDIsStableAcrossAttempts(t *testing.T) {
+	var mu sync.Mutex
+	var headers []http.Header
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		headers = append(headers, r.Header.Clone())
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	u, _ := url.Parse(srv.URL)
+	p := &RetryPolicy{MaxAttempts: 3, BaseBackoff: time.Millisecond, RetryableStatusCodes: []int{http.StatusServiceUnavailable}}
+	c := NewClientWithRetryPolicy(u, p)
+
+	req, _ := c.CreateRequest(http.MethodPost, "v1/organisation/accounts", map[string]string{"id": "fakeID"})
+	req.Header.Set(IdempotencyKeyHeader, "fakeID")
+	_, err := c.Do(context.Background(), req, nil)
+
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("unexpected error type, got %v", err)
+	}
+
+	if len(headers) != 3 {
+		t.Fatalf("unexpected attempts, expected 3 got %d", len(headers))
+	}
+
+	id := headers[0].Get(RequestIDHeader)
+	if id == "" {
+		t.Fatal("request id not sent")
+	}
+
+	for i, h := range headers {
+		if got := h.Get(RequestIDHeader); got != id {
+			t.Errorf("request id changed on attempt %d, expected %s got %s", i+1, id, got)
+		}
+
+		if got, want := h.Get(AttemptHeader), strconv.Itoa(i+1); got != want {
+			t.Errorf("unexpected attempt header, expected %s got %s", want, got)
+		}
+	}
+
+	if apiErr.RequestID != id {
+		t.Errorf("api error request id does not match, expected %s got %s", id, apiErr.RequestID)
+	}
+
+	if req.Header.Get(RequestIDHeader) != "" {
+		t.Error("caller request headers were mutated")
+	}
+}
+
+func TestClient_RequestIDIsTakenFromContext(t *testing.T) {
+	var got string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Get(RequestIDHeader)
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer srv.Close()
+
+	u, _ := url.Parse(srv.URL)
+	c := NewClientWithUrl(u)
+
+	req, _ := c.CreateRequest(http.MethodGet, "v1/organisation/accounts", nil)
+	if _, err := c.Do(ContextWithRequestID(context.Background(), "fakeRequestID"), req, nil); err != nil {
+		t.Fatalf("unexpected error on request, error %v", err)
+	}
+
+	if got != "fakeRequestID" {
+		t.Errorf("unexpected request id, got %s", got)
+	}
+}
+
+func TestClient_RequestErrorCarriesRequestID(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	u, _ := url.Parse(srv.URL)
+	srv.Close()
+
+	c := NewClientWithUrl(u)
+	req, _ := c.CreateRequest(http.MethodGet, "v1/organisation/accounts", nil)
+	req.Header.Set(RequestIDHeader, "fakeRequestID")
+
+	_, err := c.Do(context.Background(), req, nil)
+
+	var reqErr *RequestError
+	if !errors.As(err, &reqErr) {
+		t.Fatalf("unexpected error type, got %v", err)
+	}
+
+	if reqErr.RequestID != "fakeRequestID" {
+		t.Errorf("unexpected request id, got %s", reqErr.RequestID)
+	}
+}
+
+func TestClient_DecodeErrorCarriesRequestID(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"data": `))
+	}))
+	defer srv.Close()
+
+	u, _ := url.Parse(srv.URL)
+	c := NewClientWithUrl(u)
+	req, _ := c.CreateRequest(http.MethodGet, "v1/organisation/accounts", nil)
+
+	v := map[string]interface{}{}
+	_, err := c.Do(ContextWithRequestID(context.Background(), "fakeRequestID"), req, &v)
+
+	var decodeErr *DecodeError
+	if !errors.As(err, &decodeErr) {
+		t.Fatalf("unexpected error type, got %v", err)
+	}
+
+	if decodeErr.RequestID != "fakeRequestID" || decodeErr.StatusCode != http.StatusOK {
+		t.Errorf("unexpected decode error, got %+v", decodeErr)
+	}
+}
@@ -0,0 +1,169 @@
+package http
+
+import (
+	"errors"
+	"io"
+	"io/ioutil"
+	"math"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+// IdempotencyKeyHeader flags a request as safe to be replayed by the server
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// RetryPolicy defines how failed requests are retried, idempotent methods are always eligible,
+// any other method is only retried when the request carries an idempotency key
+type RetryPolicy struct {
+	// MaxAttempts includes first attempt, values lower than 2 disable retries
+	MaxAttempts int
+	// BaseBackoff is the first retry wait, doubled on each attempt
+	BaseBackoff time.Duration
+	// MaxBackoff caps exponential backoff
+	MaxBackoff time.Duration
+	// Jitter randomizes backoff as a fraction in [0, 1] of the computed wait
+	Jitter float64
+	// RetryableStatusCodes triggers a retry on matching response status codes
+	RetryableStatusCodes []int
+	// RetryableErrors triggers a retry on transport errors matched through errors.Is
+	RetryableErrors []error
+}
+
+// DefaultRetryPolicy retries throttling, server and connection errors up to 3 attempts
+func DefaultRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts: 3,
+		BaseBackoff: 100 * time.Millisecond,
+		MaxBackoff:  2 * time.Second,
+		Jitter:      0.2,
+		RetryableStatusCodes: []int{
+			http.StatusTooManyRequests,
+			http.StatusInternalServerError,
+			http.StatusBadGateway,
+			http.StatusServiceUnavailable,
+			http.StatusGatewayTimeout,
+		},
+		RetryableErrors: []error{
+			syscall.ECONNRESET,
+			syscall.ECONNREFUSED,
+			io.ErrUnexpectedEOF,
+			io.EOF,
+		},
+	}
+}
+
+// allows checks if request can be replayed
+func (p *RetryPolicy) allows(req *http.Request) bool {
+	if p == nil || p.MaxAttempts < 2 {
+		return false
+	}
+
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return false
+	}
+
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodDelete, http.MethodPut:
+		return true
+	}
+
+	return req.Header.Get(IdempotencyKeyHeader) != ""
+}
+
+// retryable checks response status code or transport error against policy
+func (p *RetryPolicy) retryable(resp *http.Response, err error) bool {
+	if err != nil {
+		for _, e := range p.RetryableErrors {
+			if errors.Is(err, e) {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	for _, code := range p.RetryableStatusCodes {
+		if resp.StatusCode == code {
+			return true
+		}
+	}
+
+	return false
+}
+
+// backoff computes wait before next attempt, Retry-After response header takes precedence
+func (p *RetryPolicy) backoff(attempt int, resp *http.Response) time.Duration {
+	if resp != nil {
+		if d, ok := retryAfter(resp.Header.Get("Retry-After"), time.Now()); ok {
+			return d
+		}
+	}
+
+	d := time.Duration(float64(p.BaseBackoff) * math.Pow(2, float64(attempt-1)))
+	if p.MaxBackoff > 0 && (d > p.MaxBackoff || d <= 0) {
+		d = p.MaxBackoff
+	}
+
+	if p.Jitter > 0 {
+		d -= time.Duration(float64(d) * p.Jitter * rand.Float64())
+	}
+
+	return d
+}
+
+// retryAfter parses Retry-After header as delay seconds or http date
+func retryAfter(v string, now time.Time) (time.Duration, bool) {
+	if v == "" {
+		return 0, false
+	}
+
+	if secs, err := strconv.Atoi(v); err == nil {
+		if secs < 0 {
+			return 0, false
+		}
+
+		return time.Duration(secs) * time.Second, true
+	}
+
+	t, err := http.ParseTime(v)
+	if err != nil {
+		return 0, false
+	}
+
+	d := t.Sub(now)
+	if d < 0 {
+		d = 0
+	}
+
+	return d, true
+}
+
+// rewind prepares request for a new attempt, restoring its body
+func rewind(req *http.Request) (*http.Request, error) {
+	if req.GetBody == nil || req.Body == nil || req.Body == http.NoBody {
+		return req, nil
+	}
+
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, err
+	}
+
+	r := req.Clone(req.Context())
+	r.Body = body
+
+	return r, nil
+}
+
+// discard drains and closes response body, enabling connection reuse
+func discard(resp *http.Response) {
+	if resp == nil || resp.Body == nil {
+		return
+	}
+
+	_, _ = io.Copy(ioutil.Discard, io.LimitReader(resp.Body, maxErrorBodySize))
+	_ = resp.Body.Close()
+}
@@ -0,0 +1,234 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestClient_DoRetriesIdempotentRequestsOnRetryableStatusCodes(t *testing.T) {
+	c := newRetryClient(t)
+	tr := &sequenceTransport{statusCodes: []int{503, 500, 200}}
+	c.client.Transport = tr
+
+	req, err := c.CreateRequest(http.MethodGet, "foo", nil)
+	if err != nil {
+		t.Fatalf("unexpected error creating request, error %v", err)
+	}
+
+	_, err = c.Do(context.Background(), req, nil)
+	if err != nil {
+		t.Fatalf("unexpected error executing request, error %v", err)
+	}
+
+	if got, want := tr.calls, 3; got != want {
+		t.Errorf("unexpected attempts, expected %d got %d", want, got)
+	}
+}
+
+func TestClient_DoStopsRetryingWhenMaxAttemptsIsReached(t *testing.T) {
+	c := newRetryClient(t)
+	tr := &sequenceTransport{statusCodes: []int{503, 503, 503, 503}}
+	c.client.Transport = tr
+
+	req, err := c.CreateRequest(http.MethodDelete, "foo", nil)
+	if err != nil {
+		t.Fatalf("unexpected error creating request, error %v", err)
+	}
+
+	_, err = c.Do(context.Background(), req, nil)
+	if !errors.Is(err, ErrServiceUnavailable) {
+		t.Fatalf("unexpected error type, expected service unavailable got %v", err)
+	}
+
+	if got, want := tr.calls, 3; got != want {
+		t.Errorf("unexpected attempts, expected %d got %d", want, got)
+	}
+}
+
+func TestClient_DoDoesNotRetryPostWithoutIdempotencyKey(t *testing.T) {
+	c := newRetryClient(t)
+	tr := &sequenceTransport{statusCodes: []int{503, 201}}
+	c.client.Transport = tr
+
+	req, err := c.CreateRequest(http.MethodPost, "foo", &fakeAccount{ID: "fakeID"})
+	if err != nil {
+		t.Fatalf("unexpected error creating request, error %v", err)
+	}
+
+	_, err = c.Do(context.Background(), req, nil)
+	if !errors.Is(err, ErrServiceUnavailable) {
+		t.Fatalf("unexpected error type, expected service unavailable got %v", err)
+	}
+
+	if got, want := tr.calls, 1; got != want {
+		t.Errorf("unexpected attempts, expected %d got %d", want, got)
+	}
+}
+
+func TestClient_DoRetriesPostWithIdempotencyKeyReplayingBody(t *testing.T) {
+	c := newRetryClient(t)
+	tr := &sequenceTransport{statusCodes: []int{503, 201}}
+	c.client.Transport = tr
+
+	req, err := c.CreateRequest(http.MethodPost, "foo", &fakeAccount{ID: "fakeID"})
+	if err != nil {
+		t.Fatalf("unexpected error creating request, error %v", err)
+	}
+	req.Header.Set(IdempotencyKeyHeader, "fakeKey")
+
+	_, err = c.Do(context.Background(), req, nil)
+	if err != nil {
+		t.Fatalf("unexpected error executing request, error %v", err)
+	}
+
+	if got, want := tr.calls, 2; got != want {
+		t.Fatalf("unexpected attempts, expected %d got %d", want, got)
+	}
+
+	if tr.bodies[0] == "" || tr.bodies[0] != tr.bodies[1] {
+		t.Errorf("request body not replayed, got %q and %q", tr.bodies[0], tr.bodies[1])
+	}
+}
+
+func TestClient_DoRetriesOnRetryableNetworkErrors(t *testing.T) {
+	c := newRetryClient(t)
+	tr := &sequenceTransport{statusCodes: []int{0, 200}, err: syscall.ECONNRESET}
+	c.client.Transport = tr
+
+	req, err := c.CreateRequest(http.MethodGet, "foo", nil)
+	if err != nil {
+		t.Fatalf("unexpected error creating request, error %v", err)
+	}
+
+	_, err = c.Do(context.Background(), req, nil)
+	if err != nil {
+		t.Fatalf("unexpected error executing request, error %v", err)
+	}
+
+	if got, want := tr.calls, 2; got != want {
+		t.Errorf("unexpected attempts, expected %d got %d", want, got)
+	}
+}
+
+func TestClient_DoStopsRetryingOnContextCancellation(t *testing.T) {
+	u, _ := url.Parse(defaultBaseURL)
+	p := DefaultRetryPolicy()
+	p.BaseBackoff = time.Hour
+	p.MaxBackoff = time.Hour
+	c := NewClientWithRetryPolicy(u, p)
+	tr := &sequenceTransport{statusCodes: []int{503, 200}}
+	c.client.Transport = tr
+
+	req, err := c.CreateRequest(http.MethodGet, "foo", nil)
+	if err != nil {
+		t.Fatalf("unexpected error creating request, error %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*20)
+	defer cancel()
+
+	_, err = c.Do(ctx, req, nil)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("unexpected error type, expected deadline exceeded got %v", err)
+	}
+
+	if got, want := tr.calls, 1; got != want {
+		t.Errorf("unexpected attempts, expected %d got %d", want, got)
+	}
+}
+
+func TestRetryPolicy_BackoffHonoursRetryAfterHeader(t *testing.T) {
+	p := DefaultRetryPolicy()
+	resp := &http.Response{Header: make(http.Header)}
+	resp.Header.Set("Retry-After", "7")
+
+	if got, want := p.backoff(1, resp), 7*time.Second; got != want {
+		t.Errorf("unexpected backoff, expected %v got %v", want, got)
+	}
+}
+
+func TestRetryPolicy_BackoffGrowsExponentiallyUpToMaxBackoff(t *testing.T) {
+	p := &RetryPolicy{
+		BaseBackoff: 100 * time.Millisecond,
+		MaxBackoff:  time.Second,
+	}
+
+	tests := []struct {
+		attempt int
+		backoff time.Duration
+	}{
+		{attempt: 1, backoff: 100 * time.Millisecond},
+		{attempt: 2, backoff: 200 * time.Millisecond},
+		{attempt: 3, backoff: 400 * time.Millisecond},
+		{attempt: 5, backoff: time.Second},
+	}
+
+	for _, test := range tests {
+		if got := p.backoff(test.attempt, nil); got != test.backoff {
+			t.Errorf("unexpected backoff on attempt %d, expected %v got %v", test.attempt, test.backoff, got)
+		}
+	}
+}
+
+func TestRetryAfter_ParsesHTTPDate(t *testing.T) {
+	now := time.Date(2020, 5, 10, 15, 0, 0, 0, time.UTC)
+	v := now.Add(30 * time.Second).Format(http.TimeFormat)
+
+	d, ok := retryAfter(v, now)
+	if !ok {
+		t.Fatal("expected parsed retry after")
+	}
+
+	if got, want := d, 30*time.Second; got != want {
+		t.Errorf("unexpected retry after, expected %v got %v", want, got)
+	}
+}
+
+func newRetryClient(t *testing.T) *Client {
+	u, err := url.Parse(defaultBaseURL)
+	if err != nil {
+		t.Fatalf("unexpected error parsing url, error %v", err)
+	}
+
+	p := DefaultRetryPolicy()
+	p.BaseBackoff = time.Millisecond
+	p.MaxBackoff = time.Millisecond * 5
+
+	return NewClientWithRetryPolicy(u, p)
+}
+
+// sequenceTransport replies status codes in order, zero status code replies err
+type sequenceTransport struct {
+	statusCodes []int
+	err         error
+	calls       int
+	bodies      []string
+}
+
+func (s *sequenceTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	statusCode := s.statusCodes[s.calls]
+	s.calls++
+
+	var body []byte
+	if req.Body != nil {
+		body, _ = ioutil.ReadAll(req.Body)
+	}
+	s.bodies = append(s.bodies, string(body))
+
+	if statusCode == 0 {
+		return nil, s.err
+	}
+
+	return &http.Response{
+		StatusCode: statusCode,
+		Body:       ioutil.NopCloser(bytes.NewBuffer(nil)),
+		Header:     make(http.Header),
+	}, nil
+}
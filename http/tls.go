@@ -0,0 +1,229 @@
+package http
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+// ErrPinMismatch happens when no server certificate matches pinned public keys
+var ErrPinMismatch = errors.New("server certificate pin mismatch")
+
+// ErrTLSTransport happens when tls configuration is set on a transport that is not an *http.Transport
+var ErrTLSTransport = errors.New("tls configuration requires an *http.Transport")
+
+// ErrInvalidCertificate happens on unparseable certificates, keys or root CAs
+var ErrInvalidCertificate = errors.New("invalid certificate")
+
+// TLSConfig defines transport security, certificates and keys are loaded from files or PEM bytes,
+// client certificate files are reloaded on handshake once they are rotated on disk
+type TLSConfig struct {
+	// CertFile and KeyFile hold client certificate and key for mutual TLS
+	CertFile string
+	KeyFile  string
+	// CertPEM and KeyPEM hold client certificate and key, files take precedence
+	CertPEM []byte
+	KeyPEM  []byte
+	// CAFile and CAPEM hold root CAs trusted to verify the server, system roots are used when empty
+	CAFile string
+	CAPEM  []byte
+	// PinnedSPKI holds base64 encoded SHA-256 hashes of trusted SubjectPublicKeyInfo,
+	// one certificate of the verified chain must match
+	PinnedSPKI []string
+	// MinVersion defaults to TLS 1.2
+	MinVersion uint16
+	// ServerName overrides server name used on verification
+	ServerName string
+}
+
+// Build creates a tls.Config from configuration
+func (c *TLSConfig) Build() (*tls.Config, error) {
+	tc := &tls.Config{
+		MinVersion: c.MinVersion,
+		ServerName: c.ServerName,
+	}
+
+	if tc.MinVersion == 0 {
+		tc.MinVersion = tls.VersionTLS12
+	}
+
+	roots, err := c.roots()
+	if err != nil {
+		return nil, err
+	}
+	tc.RootCAs = roots
+
+	if len(c.PinnedSPKI) > 0 {
+		tc.VerifyPeerCertificate = verifyPins(c.PinnedSPKI)
+	}
+
+	switch {
+	case c.CertFile != "" || c.KeyFile != "":
+		r := &certReloader{certFile: c.CertFile, keyFile: c.KeyFile}
+		if _, err := r.certificate(); err != nil {
+			return nil, err
+		}
+		tc.GetClientCertificate = func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			return r.certificate()
+		}
+	case len(c.CertPEM) > 0 || len(c.KeyPEM) > 0:
+		cert, err := tls.X509KeyPair(c.CertPEM, c.KeyPEM)
+		if err != nil {
+			return nil, fmt.Errorf("client certificate %v, error %w", err, ErrInvalidCertificate)
+		}
+		tc.Certificates = []tls.Certificate{cert}
+	}
+
+	return tc, nil
+}
+
+// roots loads trusted root CAs, nil means system roots
+func (c *TLSConfig) roots() (*x509.CertPool, error) {
+	pem := c.CAPEM
+	if c.CAFile != "" {
+		b, err := ioutil.ReadFile(c.CAFile)
+		if err != nil {
+			return nil, err
+		}
+		pem = b
+	}
+
+	if len(pem) == 0 {
+		return nil, nil
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("no root CA found, error %w", ErrInvalidCertificate)
+	}
+
+	return pool, nil
+}
+
+// SPKIHash returns base64 encoded SHA-256 hash of certificate SubjectPublicKeyInfo, as used on pins
+func SPKIHash(cert *x509.Certificate) string {
+	h := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+
+	return base64.StdEncoding.EncodeToString(h[:])
+}
+
+// verifyPins checks verified chains against pinned public key hashes
+func verifyPins(pins []string) func([][]byte, [][]*x509.Certificate) error {
+	pinned := make(map[string]bool, len(pins))
+	for _, p := range pins {
+		pinned[p] = true
+	}
+
+	return func(_ [][]byte, chains [][]*x509.Certificate) error {
+		for _, chain := range chains {
+			for _, cert := range chain {
+				if pinned[SPKIHash(cert)] {
+					return nil
+				}
+			}
+		}
+
+		return ErrPinMismatch
+	}
+}
+
+// certReloader loads client certificate from files, reloading it when files are modified
+type certReloader struct {
+	certFile string
+	keyFile  string
+	mu       sync.Mutex
+	cert     *tls.Certificate
+	modTime  time.Time
+}
+
+// certificate returns current client certificate, reloading it when files changed since last load,
+// a failed reload keeps serving previous certificate
+func (r *certReloader) certificate() (*tls.Certificate, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	modTime, err := latestModTime(r.certFile, r.keyFile)
+	if err != nil && r.cert == nil {
+		return nil, err
+	}
+
+	if r.cert != nil && (err != nil || !modTime.After(r.modTime)) {
+		return r.cert, nil
+	}
+
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		if r.cert != nil {
+			return r.cert, nil
+		}
+
+		return nil, fmt.Errorf("client certificate %v, error %w", err, ErrInvalidCertificate)
+	}
+
+	r.cert = &cert
+	r.modTime = modTime
+
+	return r.cert, nil
+}
+
+// latestModTime returns most recent modification time of files
+func latestModTime(files ...string) (time.Time, error) {
+	var latest time.Time
+	for _, f := range files {
+		fi, err := os.Stat(f)
+		if err != nil {
+			return time.Time{}, err
+		}
+
+		if fi.ModTime().After(latest) {
+			latest = fi.ModTime()
+		}
+	}
+
+	return latest, nil
+}
+
+// WithTLSConfig sets underlying transport tls configuration on an *http.Transport clone, default
+// transport is used when none is set, configuration is kept and applied again when transport is replaced
+// later, custom round trippers are never replaced, requests fail with ErrTLSTransport instead
+func WithTLSConfig(tc *tls.Config) Option {
+	return func(c *Client) {
+		c.tlsConfig = tc
+		c.applyTLS()
+	}
+}
+
+// applyTLS sets tls configuration on a clone of current transport, a transport that is not an
+// *http.Transport is kept and the error is recorded to be returned on requests
+func (c *Client) applyTLS() {
+	c.tlsErr = nil
+	if c.tlsConfig == nil {
+		return
+	}
+
+	rt := c.client.Transport
+	if rt == nil {
+		rt = http.DefaultTransport
+	}
+
+	base, ok := rt.(*http.Transport)
+	if !ok {
+		c.tlsErr = fmt.Errorf("transport %T, error %w", rt, ErrTLSTransport)
+		return
+	}
+
+	t := base.Clone()
+	t.TLSClientConfig = c.tlsConfig
+
+	hc := *c.client
+	hc.Transport = t
+	c.client = &hc
+}
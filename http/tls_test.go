@@ -0,0 +1,328 @@
+package http
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestTLSConfig_CustomRootCA(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	if _, err := get(t, srv, &TLSConfig{}); err == nil {
+		t.Error("expected error verifying server signed by unknown authority")
+	}
+
+	if _, err := get(t, srv, &TLSConfig{CAPEM: certPEM(srv.Certificate())}); err != nil {
+		t.Errorf("unexpected error with custom root CA, error %v", err)
+	}
+}
+
+func TestTLSConfig_PinnedSPKI(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	cfg := &TLSConfig{
+		CAPEM:      certPEM(srv.Certificate()),
+		PinnedSPKI: []string{SPKIHash(srv.Certificate())},
+	}
+	if _, err := get(t, srv, cfg); err != nil {
+		t.Errorf("unexpected error with matching pin, error %v", err)
+	}
+
+	cfg.PinnedSPKI = []string{"47DEQpj8HBSa+/TImW+5JCeuQeRkm5NMpJWZG3hSuFU="}
+	if _, err := get(t, srv, cfg); !errors.Is(err, ErrPinMismatch) {
+		t.Errorf("unexpected error type, expected pin mismatch got %v", err)
+	}
+}
+
+func TestTLSConfig_MinVersion(t *testing.T) {
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	srv.TLS = &tls.Config{MaxVersion: tls.VersionTLS12}
+	srv.StartTLS()
+	defer srv.Close()
+
+	cfg := &TLSConfig{CAPEM: certPEM(srv.Certificate())}
+	if _, err := get(t, srv, cfg); err != nil {
+		t.Errorf("unexpected error on default min version, error %v", err)
+	}
+
+	cfg.MinVersion = tls.VersionTLS13
+	if _, err := get(t, srv, cfg); err == nil {
+		t.Error("expected error negotiating a version lower than min version")
+	}
+}
+
+func TestTLSConfig_MutualTLSFromPEM(t *testing.T) {
+	ca, caKey := newCA(t)
+	srv := newMutualTLSServer(ca)
+	defer srv.Close()
+
+	cfg := &TLSConfig{CAPEM: certPEM(srv.Certificate())}
+	if _, err := get(t, srv, cfg); err == nil {
+		t.Error("expected error on missing client certificate")
+	}
+
+	cfg.CertPEM, cfg.KeyPEM = issue(t, ca, caKey, "fakeClient")
+	cn, err := get(t, srv, cfg)
+	if err != nil {
+		t.Fatalf("unexpected error on mutual tls, error %v", err)
+	}
+
+	if cn != "fakeClient" {
+		t.Errorf("client certificate does not match, expected fakeClient got %s", cn)
+	}
+}
+
+func TestTLSConfig_ReloadsRotatedClientCertificates(t *testing.T) {
+	ca, caKey := newCA(t)
+	srv := newMutualTLSServer(ca)
+	defer srv.Close()
+
+	dir, err := ioutil.TempDir("", "finn-tls")
+	if err != nil {
+		t.Fatalf("unexpected error creating temp dir, error %v", err)
+	}
+	defer func() {
+		_ = os.RemoveAll(dir)
+	}()
+
+	cfg := &TLSConfig{
+		CAPEM:    certPEM(srv.Certificate()),
+		CertFile: filepath.Join(dir, "client.crt"),
+		KeyFile:  filepath.Join(dir, "client.key"),
+	}
+	writeKeyPair(t, cfg, ca, caKey, "client-1", time.Now())
+
+	tc, err := cfg.Build()
+	if err != nil {
+		t.Fatalf("unexpected error building tls config, error %v", err)
+	}
+	c := newServerClient(t, srv)
+	WithTLSConfig(tc)(c)
+
+	for _, test := range []struct {
+		cn      string
+		modTime time.Time
+	}{
+		{cn: "client-1"},
+		{cn: "client-2", modTime: time.Now().Add(time.Minute)},
+	} {
+		if !test.modTime.IsZero() {
+			writeKeyPair(t, cfg, ca, caKey, test.cn, test.modTime)
+		}
+
+		req, _ := c.CreateRequest(http.MethodGet, "v1/organisation/accounts", nil)
+		resp, err := c.Do(context.Background(), req, nil)
+		if err != nil {
+			t.Fatalf("unexpected error on mutual tls, error %v", err)
+		}
+
+		if got := resp.Header.Get("X-Client-CN"); got != test.cn {
+			t.Errorf("client certificate does not match, expected %s got %s", test.cn, got)
+		}
+	}
+}
+
+func TestTLSConfig_InvalidMaterial(t *testing.T) {
+	tests := []*TLSConfig{
+		{CAPEM: []byte("not a certificate")},
+		{CertPEM: []byte("not a certificate"), KeyPEM: []byte("not a key")},
+		{CertFile: "missing.crt", KeyFile: "missing.key"},
+	}
+
+	for i, cfg := range tests {
+		if _, err := cfg.Build(); err == nil {
+			t.Errorf("expected error building invalid config %d", i)
+		}
+	}
+}
+
+// get requests server using tls configuration, returning client certificate common name seen by server
+func TestWithTLSConfig_KeepsCustomTransportOnAnyOptionOrder(t *testing.T) {
+	tc := &tls.Config{MinVersion: tls.VersionTLS12}
+	called := false
+	custom := RoundTripFunc(func(req *http.Request) (*http.Response, error) {
+		called = true
+		return nil, errors.New("unexpected round trip")
+	})
+
+	for name, opts := range map[string][]Option{
+		"transport first": {WithTransport(custom), WithTLSConfig(tc)},
+		"tls first":       {WithTLSConfig(tc), WithTransport(custom)},
+	} {
+		u, _ := url.Parse("https://fake/")
+		c := NewClientWithUrl(u, opts...)
+
+		if _, ok := c.client.Transport.(RoundTripFunc); !ok {
+			t.Errorf("%s: custom transport replaced by %T", name, c.client.Transport)
+		}
+
+		req, _ := c.CreateRequest(http.MethodGet, "v1/organisation/accounts", nil)
+		_, err := c.Do(context.Background(), req, nil)
+		if !errors.Is(err, ErrTLSTransport) {
+			t.Errorf("%s: expected tls transport error, got %v", name, err)
+		}
+	}
+
+	if called {
+		t.Error("request sent through transport without tls configuration")
+	}
+}
+
+func TestWithTLSConfig_AppliesOnHTTPTransportOnAnyOptionOrder(t *testing.T) {
+	tc := &tls.Config{MinVersion: tls.VersionTLS12}
+
+	for name, opts := range map[string][]Option{
+		"transport first": {WithTransport(&http.Transport{}), WithTLSConfig(tc)},
+		"tls first":       {WithTLSConfig(tc), WithTransport(&http.Transport{})},
+	} {
+		u, _ := url.Parse("https://fake/")
+		c := NewClientWithUrl(u, opts...)
+
+		tr, ok := c.client.Transport.(*http.Transport)
+		if !ok {
+			t.Fatalf("%s: unexpected transport %T", name, c.client.Transport)
+		}
+
+		if tr.TLSClientConfig != tc {
+			t.Errorf("%s: tls configuration not applied", name)
+		}
+
+		if c.tlsErr != nil {
+			t.Errorf("%s: unexpected tls error %v", name, c.tlsErr)
+		}
+	}
+}
+
+func get(t *testing.T, srv *httptest.Server, cfg *TLSConfig) (string, error) {
+	tc, err := cfg.Build()
+	if err != nil {
+		t.Fatalf("unexpected error building tls config, error %v", err)
+	}
+
+	u, _ := url.Parse(srv.URL)
+	c := NewClientWithUrl(u, WithTLSConfig(tc))
+	req, err := c.CreateRequest(http.MethodGet, "v1/organisation/accounts", nil)
+	if err != nil {
+		t.Fatalf("unexpected error creating request, error %v", err)
+	}
+
+	resp, err := c.Do(context.Background(), req, nil)
+	if err != nil {
+		return "", err
+	}
+
+	return resp.Header.Get("X-Client-CN"), nil
+}
+
+// newMutualTLSServer requires client certificates issued by ca, each connection serves a single request
+func newMutualTLSServer(ca *x509.Certificate) *httptest.Server {
+	pool := x509.NewCertPool()
+	pool.AddCert(ca)
+
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Connection", "close")
+		w.Header().Set("X-Client-CN", r.TLS.PeerCertificates[0].Subject.CommonName)
+	}))
+	srv.TLS = &tls.Config{
+		ClientAuth: tls.RequireAndVerifyClientCert,
+		ClientCAs:  pool,
+	}
+	srv.StartTLS()
+
+	return srv
+}
+
+func newCA(t *testing.T) (*x509.Certificate, *ecdsa.PrivateKey) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("unexpected error generating key, error %v", err)
+	}
+
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "fakeCA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, key.Public(), key)
+	if err != nil {
+		t.Fatalf("unexpected error creating ca, error %v", err)
+	}
+
+	ca, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("unexpected error parsing ca, error %v", err)
+	}
+
+	return ca, key
+}
+
+// issue creates a client certificate signed by ca, returning certificate and key PEM
+func issue(t *testing.T, ca *x509.Certificate, caKey *ecdsa.PrivateKey, cn string) ([]byte, []byte) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("unexpected error generating key, error %v", err)
+	}
+
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: cn},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, ca, key.Public(), caKey)
+	if err != nil {
+		t.Fatalf("unexpected error creating certificate, error %v", err)
+	}
+
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatalf("unexpected error marshalling key, error %v", err)
+	}
+
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER})
+}
+
+// writeKeyPair writes a new client certificate to config files with modTime
+func writeKeyPair(t *testing.T, cfg *TLSConfig, ca *x509.Certificate, caKey *ecdsa.PrivateKey, cn string, modTime time.Time) {
+	cert, key := issue(t, ca, caKey, cn)
+	for file, data := range map[string][]byte{cfg.CertFile: cert, cfg.KeyFile: key} {
+		if err := ioutil.WriteFile(file, data, 0600); err != nil {
+			t.Fatalf("unexpected error writing %s, error %v", file, err)
+		}
+
+		if err := os.Chtimes(file, modTime, modTime); err != nil {
+			t.Fatalf("unexpected error touching %s, error %v", file, err)
+		}
+	}
+}
+
+func certPEM(cert *x509.Certificate) []byte {
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw})
+}
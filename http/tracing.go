@@ -0,0 +1,55 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/marcosQuesada/finn/trace"
+)
+
+// RetriesAttribute is set on the span an attempt span is started from, holding retries done so far
+const RetriesAttribute = "finn.retries"
+
+// WithTracer starts a span per request attempt on t, attempt spans wrap every middleware, by default
+// a trace.Noop tracer is used, which still propagates trace context found on request context
+func WithTracer(t trace.Tracer) Option {
+	return func(c *Client) {
+		c.tracer = t
+	}
+}
+
+// TracingMiddleware starts a span per request attempt, child of request context span, injecting
+// W3C traceparent and tracestate headers, attempt number and status code are recorded on the span
+// and retries on its parent span
+func TracingMiddleware(t trace.Tracer) Middleware {
+	return func(next RoundTripFunc) RoundTripFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			attempt := Attempt(req.Context())
+			if attempt > 0 {
+				trace.SpanFromContext(req.Context()).SetAttribute(RetriesAttribute, attempt-1)
+			}
+
+			ctx, span := t.Start(req.Context(), "HTTP "+req.Method)
+			defer span.End()
+
+			span.SetAttribute("http.method", req.Method)
+			span.SetAttribute("http.url", req.URL.String())
+			span.SetAttribute("http.attempt", attempt)
+
+			r := req.Clone(ctx)
+			trace.Inject(ctx, r.Header)
+
+			resp, err := next(r)
+			if err != nil {
+				span.RecordError(err)
+				return resp, err
+			}
+
+			span.SetAttribute("http.status_code", resp.StatusCode)
+			if statusErr := statusError(resp.StatusCode); statusErr != nil {
+				span.RecordError(statusErr)
+			}
+
+			return resp, nil
+		}
+	}
+}
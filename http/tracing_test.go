@@ -0,0 +1,107 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/marcosQuesada/finn/trace"
+)
+
+func TestTracingMiddleware_StartsASpanPerAttempt(t *testing.T) {
+	var mu sync.Mutex
+	var traceparents []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		traceparents = append(traceparents, r.Header.Get(trace.TraceparentHeader))
+		if len(traceparents) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer srv.Close()
+
+	rec := trace.NewRecorder()
+	u, _ := url.Parse(srv.URL)
+	p := &RetryPolicy{MaxAttempts: 2, BaseBackoff: time.Millisecond, RetryableStatusCodes: []int{http.StatusServiceUnavailable}}
+	c := NewClientWithRetryPolicy(u, p, WithTracer(rec))
+
+	ctx, op := rec.Start(context.Background(), "op")
+	req, _ := c.CreateRequest(http.MethodGet, "v1/organisation/accounts/fakeID", nil)
+	if _, err := c.Do(ctx, req, nil); err != nil {
+		t.Fatalf("unexpected error on request, error %v", err)
+	}
+	op.End()
+
+	attempts := rec.Named("HTTP GET")
+	if len(attempts) != 2 {
+		t.Fatalf("unexpected attempt spans, expected 2 got %d", len(attempts))
+	}
+
+	for i, s := range attempts {
+		if s.Parent.SpanID != op.SpanContext().SpanID || s.SpanContext.TraceID != op.SpanContext().TraceID {
+			t.Errorf("attempt span %d is not an operation child", i)
+		}
+
+		if got, want := traceparents[i], trace.Traceparent(s.SpanContext); got != want {
+			t.Errorf("unexpected traceparent on attempt %d, expected %s got %s", i, want, got)
+		}
+
+		if got := s.Attributes["http.attempt"]; got != i+1 {
+			t.Errorf("unexpected attempt attribute on span %d, got %v", i, got)
+		}
+	}
+
+	if got := attempts[0].Attributes["http.status_code"]; got != http.StatusServiceUnavailable {
+		t.Errorf("unexpected first attempt status code, got %v", got)
+	}
+
+	if len(attempts[0].Errors) != 1 || len(attempts[1].Errors) != 0 {
+		t.Errorf("unexpected recorded errors, got %v and %v", attempts[0].Errors, attempts[1].Errors)
+	}
+
+	if got := rec.Named("op")[0].Attributes[RetriesAttribute]; got != 1 {
+		t.Errorf("unexpected retries on operation span, got %v", got)
+	}
+}
+
+func TestTracingMiddleware_NoopTracerPropagatesContext(t *testing.T) {
+	var got http.Header
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Clone()
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer srv.Close()
+
+	remote, err := trace.ParseTraceparent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	if err != nil {
+		t.Fatalf("unexpected error parsing traceparent, error %v", err)
+	}
+	remote.TraceState = "congo=t61rcWkgMzE"
+
+	u, _ := url.Parse(srv.URL)
+	c := NewClientWithUrl(u)
+	req, _ := c.CreateRequest(http.MethodGet, "v1/organisation/accounts/fakeID", nil)
+	if _, err := c.Do(trace.ContextWithRemoteSpanContext(context.Background(), remote), req, nil); err != nil {
+		t.Fatalf("unexpected error on request, error %v", err)
+	}
+
+	if tp := got.Get(trace.TraceparentHeader); tp != trace.Traceparent(remote) {
+		t.Errorf("unexpected traceparent, got %s", tp)
+	}
+
+	if ts := got.Get(trace.TracestateHeader); ts != remote.TraceState {
+		t.Errorf("unexpected tracestate, got %s", ts)
+	}
+
+	if req.Header.Get(trace.TraceparentHeader) != "" {
+		t.Error("caller request headers were mutated")
+	}
+}
@@ -0,0 +1,68 @@
+package iban
+
+import (
+	"errors"
+	"strings"
+)
+
+// ErrInvalidBIC happens on BIC not matching ISO 9362 structure
+var ErrInvalidBIC = errors.New("invalid bic")
+
+// BIC holds a parsed ISO 9362 business identifier code
+type BIC struct {
+	BankCode     string
+	CountryCode  string
+	LocationCode string
+	BranchCode   string
+}
+
+// ParseBIC validates 8 or 11 characters BIC structure, spaces are ignored and letters upper cased
+func ParseBIC(s string) (*BIC, error) {
+	s = normalize(s)
+	if len(s) != 8 && len(s) != 11 {
+		return nil, ErrInvalidBIC
+	}
+
+	b := &BIC{
+		BankCode:     s[:4],
+		CountryCode:  s[4:6],
+		LocationCode: s[6:8],
+	}
+
+	if len(s) == 11 {
+		b.BranchCode = s[8:]
+	}
+
+	letters := segment{kind: 'a'}
+	for i := 0; i < 6; i++ {
+		if !letters.accepts(s[i]) {
+			return nil, ErrInvalidBIC
+		}
+	}
+
+	alphanumeric := segment{kind: 'c'}
+	for i := 6; i < len(s); i++ {
+		if !alphanumeric.accepts(s[i]) {
+			return nil, ErrInvalidBIC
+		}
+	}
+
+	return b, nil
+}
+
+// ValidateBIC checks BIC string
+func ValidateBIC(s string) error {
+	_, err := ParseBIC(s)
+
+	return err
+}
+
+// String returns BIC as 8 or 11 characters
+func (b *BIC) String() string {
+	return strings.Join([]string{b.BankCode, b.CountryCode, b.LocationCode, b.BranchCode}, "")
+}
+
+// IsPrimaryOffice checks if BIC points to a primary office, with no or XXX branch code
+func (b *BIC) IsPrimaryOffice() bool {
+	return b.BranchCode == "" || b.BranchCode == "XXX"
+}
@@ -0,0 +1,57 @@
+package iban
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseBICAcceptsEightAndElevenCharacters(t *testing.T) {
+	tests := []struct {
+		bic     string
+		branch  string
+		primary bool
+	}{
+		{bic: "NWBKGB22", branch: "", primary: true},
+		{bic: "DEUTDEFF500", branch: "500", primary: false},
+		{bic: "CHASUS33XXX", branch: "XXX", primary: true},
+	}
+
+	for _, test := range tests {
+		b, err := ParseBIC(test.bic)
+		if err != nil {
+			t.Errorf("unexpected error parsing %s, error %v", test.bic, err)
+			continue
+		}
+
+		if got := b.String(); got != test.bic {
+			t.Errorf("bic does not match, expected %s got %s", test.bic, got)
+		}
+
+		if got := b.BranchCode; got != test.branch {
+			t.Errorf("branch code does not match, expected %s got %s", test.branch, got)
+		}
+
+		if got := b.IsPrimaryOffice(); got != test.primary {
+			t.Errorf("unexpected primary office on %s, expected %t got %t", test.bic, test.primary, got)
+		}
+	}
+}
+
+func TestParseBICSplitsCodes(t *testing.T) {
+	b, err := ParseBIC("deut de ff 500")
+	if err != nil {
+		t.Fatalf("unexpected error parsing bic, error %v", err)
+	}
+
+	if b.BankCode != "DEUT" || b.CountryCode != "DE" || b.LocationCode != "FF" || b.BranchCode != "500" {
+		t.Errorf("unexpected bic codes, got %+v", b)
+	}
+}
+
+func TestParseBICRejectsInvalidStructure(t *testing.T) {
+	for _, s := range []string{"NWBK", "NWBKGB2", "NWBKGB22X", "NW1KGB22", "NWBKG122", "NWBKGB2-"} {
+		if err := ValidateBIC(s); !errors.Is(err, ErrInvalidBIC) {
+			t.Errorf("unexpected error on %s, expected invalid bic got %v", s, err)
+		}
+	}
+}
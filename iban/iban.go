@@ -0,0 +1,162 @@
+package iban
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// ErrUnsupportedCountry happens on countries without a registered IBAN structure
+var ErrUnsupportedCountry = errors.New("unsupported iban country")
+
+// ErrInvalidLength happens when IBAN length does not match country length
+var ErrInvalidLength = errors.New("invalid iban length")
+
+// ErrInvalidFormat happens when BBAN does not match country structure
+var ErrInvalidFormat = errors.New("invalid iban format")
+
+// ErrInvalidChecksum happens on ISO 7064 mod-97 check failure
+var ErrInvalidChecksum = errors.New("invalid iban checksum")
+
+// ErrGenerationNotSupported happens when country scheme does not allow deriving an IBAN
+var ErrGenerationNotSupported = errors.New("iban generation not supported")
+
+// IBAN holds a parsed international bank account number
+type IBAN struct {
+	CountryCode string
+	CheckDigits string
+	BBAN        string
+}
+
+// Parse validates IBAN length, BBAN structure and checksum, spaces are ignored and letters upper cased
+func Parse(s string) (*IBAN, error) {
+	s = normalize(s)
+	if len(s) < 4 {
+		return nil, ErrInvalidLength
+	}
+
+	st, ok := structures[s[:2]]
+	if !ok {
+		return nil, fmt.Errorf("country %q, error %w", s[:2], ErrUnsupportedCountry)
+	}
+
+	if len(s) != st.length() {
+		return nil, fmt.Errorf("expected %d characters got %d, error %w", st.length(), len(s), ErrInvalidLength)
+	}
+
+	i := &IBAN{
+		CountryCode: s[:2],
+		CheckDigits: s[2:4],
+		BBAN:        s[4:],
+	}
+
+	if !isDigits(i.CheckDigits) || !st.matches(i.BBAN) {
+		return nil, ErrInvalidFormat
+	}
+
+	if mod97(i.BBAN+i.CountryCode+i.CheckDigits) != 1 {
+		return nil, ErrInvalidChecksum
+	}
+
+	return i, nil
+}
+
+// Validate checks IBAN string
+func Validate(s string) error {
+	_, err := Parse(s)
+
+	return err
+}
+
+// String returns electronic format
+func (i *IBAN) String() string {
+	return i.Electronic()
+}
+
+// Electronic returns IBAN without separators, as used on payment messages
+func (i *IBAN) Electronic() string {
+	return i.CountryCode + i.CheckDigits + i.BBAN
+}
+
+// Print returns IBAN in groups of four characters, as used on paper
+func (i *IBAN) Print() string {
+	s := i.Electronic()
+	groups := make([]string, 0, len(s)/4+1)
+	for len(s) > 4 {
+		groups = append(groups, s[:4])
+		s = s[4:]
+	}
+
+	return strings.Join(append(groups, s), " ")
+}
+
+// Generate derives an IBAN from country, bank id and account number where the scheme allows it,
+// national check digits are computed when required, GB and IE bank id has to be prefixed by BIC bank code
+func Generate(country, bankID, accountNumber string) (*IBAN, error) {
+	country = strings.ToUpper(country)
+	st, ok := structures[country]
+	if !ok {
+		return nil, fmt.Errorf("country %q, error %w", country, ErrUnsupportedCountry)
+	}
+
+	build, ok := generators[country]
+	if !ok {
+		return nil, fmt.Errorf("country %q, error %w", country, ErrGenerationNotSupported)
+	}
+
+	bban, err := build(normalize(bankID), normalize(accountNumber))
+	if err != nil {
+		return nil, err
+	}
+
+	if !st.matches(bban) {
+		return nil, ErrInvalidFormat
+	}
+
+	return &IBAN{
+		CountryCode: country,
+		CheckDigits: checkDigits(country, bban),
+		BBAN:        bban,
+	}, nil
+}
+
+// checkDigits computes ISO 7064 mod-97 check digits
+func checkDigits(country, bban string) string {
+	return fmt.Sprintf("%02d", 98-mod97(bban+country+"00"))
+}
+
+// mod97 computes ISO 7064 mod-97 remainder, letters are expanded as A=10 to Z=35
+func mod97(s string) int {
+	var b strings.Builder
+	for _, r := range s {
+		if r >= 'A' && r <= 'Z' {
+			fmt.Fprintf(&b, "%d", r-'A'+10)
+			continue
+		}
+		b.WriteRune(r)
+	}
+
+	n, ok := new(big.Int).SetString(b.String(), 10)
+	if !ok {
+		return -1
+	}
+
+	return int(new(big.Int).Mod(n, big.NewInt(97)).Int64())
+}
+
+// normalize removes separators and upper cases
+func normalize(s string) string {
+	return strings.ToUpper(strings.Join(strings.Fields(s), ""))
+}
+
+// isDigits checks s holds only digits
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+
+	return s != ""
+}
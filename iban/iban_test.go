@@ -0,0 +1,129 @@
+package iban
+
+import (
+	"errors"
+	"testing"
+)
+
+var validIBANs = []string{
+	"GB82WEST12345698765432",
+	"DE89370400440532013000",
+	"FR1420041010050500013M02606",
+	"ES9121000418450200051332",
+	"BE68539007547034",
+	"NL91ABNA0417164300",
+	"CH9300762011623852957",
+	"IT60X0542811101000000123456",
+	"PL61109010140000071219812874",
+	"PT50000201231234567890154",
+	"LU280019400644750000",
+	"GR1601101250000000012300695",
+	"AT611904300234573201",
+}
+
+func TestParseAcceptsValidIBANs(t *testing.T) {
+	for _, s := range validIBANs {
+		i, err := Parse(s)
+		if err != nil {
+			t.Errorf("unexpected error parsing %s, error %v", s, err)
+			continue
+		}
+
+		if got := i.Electronic(); got != s {
+			t.Errorf("electronic format does not match, expected %s got %s", s, got)
+		}
+	}
+}
+
+func TestParseNormalizesPrintFormat(t *testing.T) {
+	i, err := Parse("gb82 west 1234 5698 7654 32")
+	if err != nil {
+		t.Fatalf("unexpected error parsing iban, error %v", err)
+	}
+
+	if got, want := i.CountryCode, "GB"; got != want {
+		t.Errorf("country code does not match, expected %s got %s", want, got)
+	}
+
+	if got, want := i.CheckDigits, "82"; got != want {
+		t.Errorf("check digits do not match, expected %s got %s", want, got)
+	}
+
+	if got, want := i.BBAN, "WEST12345698765432"; got != want {
+		t.Errorf("bban does not match, expected %s got %s", want, got)
+	}
+
+	if got, want := i.Print(), "GB82 WEST 1234 5698 7654 32"; got != want {
+		t.Errorf("print format does not match, expected %s got %s", want, got)
+	}
+}
+
+func TestParseRejectsInvalidIBANs(t *testing.T) {
+	tests := []struct {
+		iban string
+		err  error
+	}{
+		{iban: "GB83WEST12345698765432", err: ErrInvalidChecksum},
+		{iban: "GB82WEST1234569876543", err: ErrInvalidLength},
+		{iban: "GB82WES112345698765432", err: ErrInvalidFormat},
+		{iban: "XX82WEST12345698765432", err: ErrUnsupportedCountry},
+		{iban: "GB", err: ErrInvalidLength},
+		{iban: "DE8937040044053201300A", err: ErrInvalidFormat},
+	}
+
+	for _, test := range tests {
+		if err := Validate(test.iban); !errors.Is(err, test.err) {
+			t.Errorf("unexpected error on %s, expected %v got %v", test.iban, test.err, err)
+		}
+	}
+}
+
+func TestGenerateDerivesIBANFromBankIDAndAccountNumber(t *testing.T) {
+	tests := []struct {
+		country       string
+		bankID        string
+		accountNumber string
+		iban          string
+	}{
+		{country: "GB", bankID: "WEST123456", accountNumber: "98765432", iban: "GB82WEST12345698765432"},
+		{country: "DE", bankID: "37040044", accountNumber: "532013000", iban: "DE89370400440532013000"},
+		{country: "ES", bankID: "21000418", accountNumber: "0200051332", iban: "ES9121000418450200051332"},
+		{country: "BE", bankID: "539", accountNumber: "0075470", iban: "BE68539007547034"},
+		{country: "NL", bankID: "ABNA", accountNumber: "417164300", iban: "NL91ABNA0417164300"},
+		{country: "PT", bankID: "00020123", accountNumber: "12345678901", iban: "PT50000201231234567890154"},
+		{country: "PL", bankID: "10901014", accountNumber: "0000071219812874", iban: "PL61109010140000071219812874"},
+		{country: "AT", bankID: "19043", accountNumber: "234573201", iban: "AT611904300234573201"},
+	}
+
+	for _, test := range tests {
+		i, err := Generate(test.country, test.bankID, test.accountNumber)
+		if err != nil {
+			t.Errorf("unexpected error generating %s iban, error %v", test.country, err)
+			continue
+		}
+
+		if got := i.Electronic(); got != test.iban {
+			t.Errorf("generated iban does not match, expected %s got %s", test.iban, got)
+		}
+	}
+}
+
+func TestGenerateRejectsUnsupportedSchemesAndInvalidInput(t *testing.T) {
+	tests := []struct {
+		country       string
+		bankID        string
+		accountNumber string
+		err           error
+	}{
+		{country: "FR", bankID: "2004101005", accountNumber: "0500013M026", err: ErrGenerationNotSupported},
+		{country: "US", bankID: "021000021", accountNumber: "123456", err: ErrUnsupportedCountry},
+		{country: "DE", bankID: "3704", accountNumber: "532013000", err: ErrInvalidFormat},
+		{country: "GB", bankID: "400300", accountNumber: "41426819", err: ErrInvalidFormat},
+	}
+
+	for _, test := range tests {
+		if _, err := Generate(test.country, test.bankID, test.accountNumber); !errors.Is(err, test.err) {
+			t.Errorf("unexpected error on %s, expected %v got %v", test.country, test.err, err)
+		}
+	}
+}
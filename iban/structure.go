@@ -0,0 +1,201 @@
+package iban
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var specPattern = regexp.MustCompile(`(\d+)!([nac])`)
+
+// segment defines a fixed length BBAN part of digits (n), upper case letters (a) or alphanumeric (c)
+type segment struct {
+	size int
+	kind byte
+}
+
+// structure defines country BBAN segments
+type structure []segment
+
+// newStructure parses SWIFT IBAN registry BBAN spec, as 4!a6!n8!n
+func newStructure(spec string) structure {
+	var st structure
+	for _, m := range specPattern.FindAllStringSubmatch(spec, -1) {
+		size, _ := strconv.Atoi(m[1])
+		st = append(st, segment{size: size, kind: m[2][0]})
+	}
+
+	return st
+}
+
+// length returns full IBAN length, including country code and check digits
+func (st structure) length() int {
+	l := 4
+	for _, s := range st {
+		l += s.size
+	}
+
+	return l
+}
+
+// matches checks BBAN against segments
+func (st structure) matches(bban string) bool {
+	if len(bban)+4 != st.length() {
+		return false
+	}
+
+	for _, s := range st {
+		part := bban[:s.size]
+		bban = bban[s.size:]
+		for i := 0; i < len(part); i++ {
+			if !s.accepts(part[i]) {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// accepts checks character against segment kind
+func (s segment) accepts(c byte) bool {
+	digit := c >= '0' && c <= '9'
+	letter := c >= 'A' && c <= 'Z'
+
+	switch s.kind {
+	case 'n':
+		return digit
+	case 'a':
+		return letter
+	}
+
+	return digit || letter
+}
+
+// structures declares SWIFT IBAN registry BBAN structures by country
+var structures = map[string]structure{
+	"AD": newStructure("4!n4!n12!c"),
+	"AE": newStructure("3!n16!n"),
+	"AL": newStructure("8!n16!c"),
+	"AT": newStructure("5!n11!n"),
+	"BA": newStructure("3!n3!n8!n2!n"),
+	"BE": newStructure("3!n7!n2!n"),
+	"BG": newStructure("4!a4!n2!n8!c"),
+	"CH": newStructure("5!n12!c"),
+	"CY": newStructure("3!n5!n16!c"),
+	"CZ": newStructure("4!n6!n10!n"),
+	"DE": newStructure("8!n10!n"),
+	"DK": newStructure("4!n9!n1!n"),
+	"EE": newStructure("2!n2!n11!n1!n"),
+	"ES": newStructure("4!n4!n1!n1!n10!n"),
+	"FI": newStructure("3!n11!n"),
+	"FR": newStructure("5!n5!n11!c2!n"),
+	"GB": newStructure("4!a6!n8!n"),
+	"GI": newStructure("4!a15!c"),
+	"GR": newStructure("3!n4!n16!c"),
+	"HR": newStructure("7!n10!n"),
+	"HU": newStructure("3!n4!n1!n15!n1!n"),
+	"IE": newStructure("4!a6!n8!n"),
+	"IS": newStructure("4!n2!n6!n10!n"),
+	"IT": newStructure("1!a5!n5!n12!c"),
+	"LI": newStructure("5!n12!c"),
+	"LT": newStructure("5!n11!n"),
+	"LU": newStructure("3!n13!c"),
+	"LV": newStructure("4!a13!c"),
+	"MC": newStructure("5!n5!n11!c2!n"),
+	"MT": newStructure("4!a5!n18!c"),
+	"NL": newStructure("4!a10!n"),
+	"NO": newStructure("4!n6!n1!n"),
+	"PL": newStructure("8!n16!n"),
+	"PT": newStructure("4!n4!n11!n2!n"),
+	"RO": newStructure("4!a16!c"),
+	"SE": newStructure("3!n16!n1!n"),
+	"SI": newStructure("5!n8!n2!n"),
+	"SK": newStructure("4!n6!n10!n"),
+	"SM": newStructure("1!a5!n5!n12!c"),
+}
+
+// generator composes a BBAN from bank id and account number
+type generator func(bankID, accountNumber string) (string, error)
+
+// generators declares countries where BBAN can be derived from bank id and account number
+var generators = map[string]generator{
+	"AT": concat(5, 11),
+	"CH": concat(5, 12),
+	"DE": concat(8, 10),
+	"GB": concat(10, 8),
+	"GR": concat(7, 16),
+	"IE": concat(10, 8),
+	"LU": concat(3, 13),
+	"NL": concat(4, 10),
+	"PL": concat(8, 16),
+	"BE": func(bankID, accountNumber string) (string, error) {
+		bban, err := concat(3, 7)(bankID, accountNumber)
+		if err != nil {
+			return "", err
+		}
+
+		check := mod97(bban)
+		if check == 0 {
+			check = 97
+		}
+
+		return fmt.Sprintf("%s%02d", bban, check), nil
+	},
+	"ES": func(bankID, accountNumber string) (string, error) {
+		if len(bankID) != 8 || !isDigits(bankID) || len(accountNumber) > 10 || !isDigits(accountNumber) {
+			return "", ErrInvalidFormat
+		}
+		accountNumber = pad(accountNumber, 10)
+
+		return fmt.Sprintf("%s%d%d%s", bankID, spanishCheck("00"+bankID), spanishCheck(accountNumber), accountNumber), nil
+	},
+	"PT": func(bankID, accountNumber string) (string, error) {
+		bban, err := concat(8, 11)(bankID, accountNumber)
+		if err != nil {
+			return "", err
+		}
+
+		return fmt.Sprintf("%s%02d", bban, 98-mod97(bban+"00")), nil
+	},
+}
+
+// concat builds BBAN as bank id followed by left zero padded account number
+func concat(bankSize, accountSize int) generator {
+	return func(bankID, accountNumber string) (string, error) {
+		if len(bankID) != bankSize || accountNumber == "" || len(accountNumber) > accountSize {
+			return "", ErrInvalidFormat
+		}
+
+		return bankID + pad(accountNumber, accountSize), nil
+	}
+}
+
+// spanishCheck computes spanish CCC control digit over 10 digits
+func spanishCheck(s string) int {
+	weights := []int{1, 2, 4, 8, 5, 10, 9, 7, 3, 6}
+	sum := 0
+	for i, w := range weights {
+		sum += int(s[i]-'0') * w
+	}
+
+	d := 11 - sum%11
+	switch d {
+	case 11:
+		return 0
+	case 10:
+		return 1
+	}
+
+	return d
+}
+
+// pad left pads s with zeros up to size
+func pad(s string, size int) string {
+	if len(s) >= size {
+		return s
+	}
+
+	return strings.Repeat("0", size-len(s)) + s
+}
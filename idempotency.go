@@ -0,0 +1,111 @@
+package finn
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// ErrDuplicateAccount happens when a created account id already exists holding different data,
+// it is returned wrapped, match it with errors.Is
+var ErrDuplicateAccount = errors.New("duplicate account")
+
+// CreateOption configures a single Create call
+type CreateOption func(*createOptions)
+
+// createOptions holds Create call options
+type createOptions struct {
+	idempotencyKey string
+}
+
+// WithIdempotencyKey sets Create idempotency key, a random one is generated by default, an empty key
+// sends no idempotency key and disables replayed conflicts resolution
+func WithIdempotencyKey(key string) CreateOption {
+	return func(o *createOptions) {
+		o.idempotencyKey = key
+	}
+}
+
+// newCreateOptions applies options over defaults
+func newCreateOptions(opts []CreateOption) *createOptions {
+	o := &createOptions{
+		idempotencyKey: uuid.New().String(),
+	}
+
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	return o
+}
+
+// replayed resolves a create conflict, when stored account matches sent one the conflict comes
+// from a replayed request and stored account is returned, otherwise ErrDuplicateAccount is returned
+func (c *APIClient) replayed(ctx context.Context, sent *Account) (*Account, error) {
+	stored, err := c.fetch(ctx, sent.AccoundData.ID)
+	if err != nil {
+		return nil, fmt.Errorf("unexpected error fetching conflicting account, error %w", err)
+	}
+
+	if !matches(sent.AccoundData, stored.AccoundData) {
+		return nil, fmt.Errorf("account %s, error %w", sent.AccoundData.ID, ErrDuplicateAccount)
+	}
+
+	return stored, nil
+}
+
+// hasID checks account declares an id, required to look up a replayed creation
+func hasID(a *Account) bool {
+	return a != nil && a.AccoundData != nil && a.AccoundData.ID != ""
+}
+
+// matches checks stored account holds every sent value, server populated fields are ignored
+func matches(sent, stored *AccoundData) bool {
+	if stored == nil || sent.ID != stored.ID || sent.Type != stored.Type || sent.OrganisationID != stored.OrganisationID {
+		return false
+	}
+
+	if sent.Attributes == nil {
+		return true
+	}
+
+	if stored.Attributes == nil {
+		return false
+	}
+
+	want, err := fields(sent.Attributes)
+	if err != nil {
+		return false
+	}
+
+	got, err := fields(stored.Attributes)
+	if err != nil {
+		return false
+	}
+
+	for k, v := range want {
+		if string(got[k]) != string(v) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// fields decodes attributes as raw json fields, empty values are omitted
+func fields(attr *Attributes) (map[string]json.RawMessage, error) {
+	raw, err := json.Marshal(attr)
+	if err != nil {
+		return nil, err
+	}
+
+	f := make(map[string]json.RawMessage)
+	if err := json.Unmarshal(raw, &f); err != nil {
+		return nil, err
+	}
+
+	return f, nil
+}
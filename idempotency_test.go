@@ -0,0 +1,165 @@
+package finn
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+
+	client "github.com/marcosQuesada/finn/http"
+	"github.com/marcosQuesada/finn/metrics"
+)
+
+func TestCreateAccountSendsGeneratedIdempotencyKey(t *testing.T) {
+	keys := make(map[string]bool)
+	for i := 0; i < 2; i++ {
+		h := &fakeHTTPClient{statusCode: http.StatusCreated}
+		api := NewAPIClient(h)
+
+		if _, err := api.Create(context.Background(), newValidAccount(&Attributes{Country: "GB"})); err != nil {
+			t.Fatalf("unexpected error creating account, error %v", err)
+		}
+
+		key := h.req.Header.Get(client.IdempotencyKeyHeader)
+		if key == "" {
+			t.Fatal("empty idempotency key")
+		}
+		keys[key] = true
+	}
+
+	if got, want := len(keys), 2; got != want {
+		t.Errorf("idempotency keys are not unique, expected %d got %d", want, got)
+	}
+}
+
+func TestCreateAccountSendsCallerIdempotencyKey(t *testing.T) {
+	h := &fakeHTTPClient{statusCode: http.StatusCreated}
+	api := NewAPIClient(h)
+
+	_, err := api.Create(context.Background(), newValidAccount(&Attributes{Country: "GB"}), WithIdempotencyKey("fakeKey"))
+	if err != nil {
+		t.Fatalf("unexpected error creating account, error %v", err)
+	}
+
+	if got, want := h.req.Header.Get(client.IdempotencyKeyHeader), "fakeKey"; got != want {
+		t.Errorf("idempotency key does not match, expected %s got %s", want, got)
+	}
+}
+
+func TestCreateAccountResolvesReplayedConflictAsSuccess(t *testing.T) {
+	acc := newValidAccount(&Attributes{Country: "GB", BankID: "400300"})
+	stored := &Account{AccoundData: &AccoundData{
+		Type:           acc.AccoundData.Type,
+		ID:             acc.AccoundData.ID,
+		OrganisationID: acc.AccoundData.OrganisationID,
+		Attributes:     &Attributes{Country: "GB", BankID: "400300", AccountNumber: "41426819"},
+	}}
+	raw, err := json.Marshal(stored)
+	if err != nil {
+		t.Fatalf("unexpected error marshalling account, error %v", err)
+	}
+
+	h := &sequenceHTTPClient{
+		responses: []*fakeHTTPClient{
+			{statusCode: http.StatusConflict, err: client.ErrConflict},
+			{statusCode: http.StatusOK, body: raw},
+		},
+	}
+	api := NewAPIClient(h)
+
+	a, err := api.Create(context.Background(), acc)
+	if err != nil {
+		t.Fatalf("unexpected error creating account, error %v", err)
+	}
+
+	if got, want := a.AccoundData.Attributes.AccountNumber, "41426819"; got != want {
+		t.Errorf("stored account not returned, expected account number %s got %s", want, got)
+	}
+}
+
+func TestCreateAccountReturnsDuplicateAccountOnDifferentStoredAccount(t *testing.T) {
+	acc := newValidAccount(&Attributes{Country: "GB", BankID: "400300"})
+	stored := &Account{AccoundData: &AccoundData{
+		Type:           acc.AccoundData.Type,
+		ID:             acc.AccoundData.ID,
+		OrganisationID: acc.AccoundData.OrganisationID,
+		Attributes:     &Attributes{Country: "GB", BankID: "400301"},
+	}}
+	raw, err := json.Marshal(stored)
+	if err != nil {
+		t.Fatalf("unexpected error marshalling account, error %v", err)
+	}
+
+	h := &sequenceHTTPClient{
+		responses: []*fakeHTTPClient{
+			{statusCode: http.StatusConflict, err: client.ErrConflict},
+			{statusCode: http.StatusOK, body: raw},
+		},
+	}
+	api := NewAPIClient(h)
+
+	_, err = api.Create(context.Background(), acc)
+	if !errors.Is(err, ErrDuplicateAccount) {
+		t.Errorf("unexpected error type, expected duplicate account got %v", err)
+	}
+}
+
+func TestCreateAccountRecordsReplayLookupAsCreateOnly(t *testing.T) {
+	acc := newValidAccount(&Attributes{Country: "GB", BankID: "400300"})
+	raw, err := json.Marshal(acc)
+	if err != nil {
+		t.Fatalf("unexpected error marshalling account, error %v", err)
+	}
+
+	h := &sequenceHTTPClient{
+		responses: []*fakeHTTPClient{
+			{statusCode: http.StatusConflict, err: client.ErrConflict},
+			{statusCode: http.StatusOK, body: raw},
+		},
+	}
+	m := metrics.NewMemory()
+	api := NewAPIClient(h, WithMetrics(m))
+
+	if _, err := api.Create(context.Background(), acc); err != nil {
+		t.Fatalf("unexpected error creating account, error %v", err)
+	}
+
+	if got := m.Value("finn_client_operations_total", opCreate, "2xx", "none"); got != 1 {
+		t.Errorf("unexpected create operations, expected 1 got %v", got)
+	}
+
+	if got := m.Value("finn_client_operation_duration_seconds", opFetch, "2xx"); got != 0 {
+		t.Errorf("replay lookup recorded as fetch operation, got %v", got)
+	}
+}
+
+func TestCreateAccountDoesNotResolveConflictsWithoutKeyOrID(t *testing.T) {
+	withID := newValidAccount(&Attributes{Country: "GB"})
+	withoutID := newValidAccount(&Attributes{Country: "GB"})
+	withoutID.AccoundData.ID = ""
+
+	for name, test := range map[string]struct {
+		acc  *Account
+		opts []CreateOption
+	}{
+		"without idempotency key": {acc: withID, opts: []CreateOption{WithIdempotencyKey("")}},
+		"without account id":      {acc: withoutID},
+	} {
+		h := &sequenceHTTPClient{
+			responses: []*fakeHTTPClient{
+				{statusCode: http.StatusConflict, err: client.ErrConflict},
+			},
+		}
+		api := NewAPIClient(h)
+
+		_, err := api.Create(context.Background(), test.acc, test.opts...)
+		if !errors.Is(err, client.ErrConflict) || errors.Is(err, ErrDuplicateAccount) {
+			t.Errorf("%s: unexpected error type, expected conflict got %v", name, err)
+		}
+
+		if h.calls != 1 {
+			t.Errorf("%s: unexpected requests, expected 1 got %d", name, h.calls)
+		}
+	}
+}
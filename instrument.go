@@ -0,0 +1,149 @@
+package finn
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	client "github.com/marcosQuesada/finn/http"
+	"github.com/marcosQuesada/finn/metrics"
+	"github.com/marcosQuesada/finn/trace"
+)
+
+// operation names label APIClient calls on instrumentation
+const (
+	opCreate = "create"
+	opFetch  = "fetch"
+	opList   = "list"
+	opUpdate = "update"
+	opDelete = "delete"
+)
+
+// WithMetrics records operation counters, latency histograms and in-flight gauges on r, labelled by
+// operation, status class and error kind
+func WithMetrics(r metrics.Registry) Option {
+	return func(c *APIClient) {
+		c.metrics = &instruments{
+			operations: r.Counter("finn_client_operations_total", "Account api operations",
+				"operation", "status_class", "error_kind"),
+			latency: r.Histogram("finn_client_operation_duration_seconds", "Account api operation latency",
+				metrics.DefaultBuckets, "operation", "status_class"),
+			inFlight: r.Gauge("finn_client_operations_in_flight", "Account api operations in flight",
+				"operation"),
+		}
+	}
+}
+
+// WithTracer starts a span per operation on t, named after the operation as account.fetch, recording
+// status class and error kind, http attempt spans started by http.WithTracer become its children
+func WithTracer(t trace.Tracer) Option {
+	return func(c *APIClient) {
+		c.tracer = t
+	}
+}
+
+// instruments holds APIClient metrics
+type instruments struct {
+	operations metrics.Counter
+	latency    metrics.Histogram
+	inFlight   metrics.Gauge
+}
+
+// observe runs an operation inside its span, recording its instrumentation, operation requests
+// carry ctx request id, a new one is bound when ctx holds none, and it is attached to returned error
+func (c *APIClient) observe(ctx context.Context, op string, fn func(ctx context.Context) error) error {
+	id := client.RequestIDFromContext(ctx)
+	if id == "" {
+		id = client.NewRequestID()
+		ctx = client.ContextWithRequestID(ctx, id)
+	}
+
+	ctx, span := c.tracer.Start(ctx, "account."+op)
+	defer span.End()
+	span.SetAttribute("finn.operation", op)
+	span.SetAttribute("finn.request_id", id)
+
+	if c.metrics != nil {
+		c.metrics.inFlight.Add(1, op)
+	}
+
+	start := time.Now()
+	err := fn(ctx)
+	class, kind := statusClass(err), errorKind(err)
+
+	span.SetAttribute("finn.status_class", class)
+	span.SetAttribute("finn.error_kind", kind)
+	span.RecordError(err)
+
+	if c.metrics != nil {
+		c.metrics.inFlight.Add(-1, op)
+		c.metrics.operations.Add(1, op, class, kind)
+		c.metrics.latency.Observe(time.Since(start).Seconds(), op, class)
+	}
+
+	return withOperation(op, id, err)
+}
+
+// statusClass returns response status class as 2xx, none is returned when no response was received
+func statusClass(err error) string {
+	if err == nil {
+		return "2xx"
+	}
+
+	var apiErr *client.APIError
+	if errors.As(err, &apiErr) {
+		return fmt.Sprintf("%dxx", apiErr.StatusCode/100)
+	}
+
+	var decodeErr *client.DecodeError
+	if errors.As(err, &decodeErr) {
+		return fmt.Sprintf("%dxx", decodeErr.StatusCode/100)
+	}
+
+	if errors.Is(err, ErrVersionConflict) || errors.Is(err, ErrDuplicateAccount) {
+		return "4xx"
+	}
+
+	return "none"
+}
+
+// errorKind classifies errors on a small label set
+func errorKind(err error) string {
+	switch {
+	case err == nil:
+		return "none"
+	case errors.Is(err, context.Canceled):
+		return "canceled"
+	case errors.Is(err, context.DeadlineExceeded):
+		return "timeout"
+	case errors.Is(err, ErrInvalidAccount):
+		return "validation"
+	case errors.Is(err, ErrVersionConflict), errors.Is(err, ErrDuplicateAccount), errors.Is(err, client.ErrConflict):
+		return "conflict"
+	case errors.Is(err, client.ErrContentNotFound):
+		return "not_found"
+	case errors.Is(err, client.ErrUnauthorized), errors.Is(err, client.ErrNotAuthorized):
+		return "unauthorized"
+	case errors.Is(err, client.ErrBadRequest):
+		return "bad_request"
+	case errors.Is(err, client.ErrTooManyRequests), errors.Is(err, client.ErrRateLimited):
+		return "throttled"
+	case errors.Is(err, client.ErrCircuitOpen):
+		return "circuit_open"
+	case errors.Is(err, client.ErrInternalServer), errors.Is(err, client.ErrServiceUnavailable):
+		return "server"
+	}
+
+	var decodeErr *client.DecodeError
+	if errors.As(err, &decodeErr) {
+		return "decode"
+	}
+
+	var reqErr *client.RequestError
+	if errors.As(err, &reqErr) {
+		return "transport"
+	}
+
+	return "unknown"
+}
@@ -0,0 +1,125 @@
+package finn
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+	client "github.com/marcosQuesada/finn/http"
+	"github.com/marcosQuesada/finn/metrics"
+	"github.com/marcosQuesada/finn/trace"
+)
+
+func TestWithMetricsRecordsSuccessfulOperations(t *testing.T) {
+	userID := uuid.New().String()
+	rawAccount, err := json.Marshal(&Account{AccoundData: &AccoundData{ID: userID}})
+	if err != nil {
+		t.Fatalf("unexpected error marshalling account, error %v", err)
+	}
+
+	m := metrics.NewMemory()
+	api := NewAPIClient(&fakeHTTPClient{statusCode: http.StatusOK, body: rawAccount}, WithMetrics(m))
+
+	for i := 0; i < 2; i++ {
+		if _, err := api.Fetch(context.Background(), userID); err != nil {
+			t.Fatalf("unexpected error fetching account, error %v", err)
+		}
+	}
+
+	if got, want := m.Value("finn_client_operations_total", opFetch, "2xx", "none"), 2.0; got != want {
+		t.Errorf("unexpected operations count, expected %v got %v", want, got)
+	}
+
+	if got, want := m.Value("finn_client_operation_duration_seconds", opFetch, "2xx"), 2.0; got != want {
+		t.Errorf("unexpected latency observations, expected %v got %v", want, got)
+	}
+
+	if got := m.Value("finn_client_operations_in_flight", opFetch); got != 0 {
+		t.Errorf("unexpected in flight operations, got %v", got)
+	}
+}
+
+func TestWithMetricsLabelsFailedOperations(t *testing.T) {
+	m := metrics.NewMemory()
+	notFound := &client.APIError{StatusCode: http.StatusNotFound}
+	api := NewAPIClient(&fakeHTTPClient{statusCode: http.StatusNotFound, err: notFound}, WithMetrics(m))
+
+	if err := api.Delete(context.Background(), uuid.New().String(), 0); err == nil {
+		t.Fatal("expected not found error")
+	}
+
+	if got, want := m.Value("finn_client_operations_total", opDelete, "4xx", "not_found"), 1.0; got != want {
+		t.Errorf("unexpected operations count, expected %v got %v", want, got)
+	}
+}
+
+func TestWithTracerStartsASpanPerOperation(t *testing.T) {
+	rec := trace.NewRecorder()
+	h := &fakeHTTPClient{statusCode: http.StatusConflict, err: &client.APIError{StatusCode: http.StatusConflict}}
+	api := NewAPIClient(h, WithTracer(rec))
+
+	ctx, parent := rec.Start(context.Background(), "caller")
+	_, err := api.Update(ctx, &Account{AccoundData: &AccoundData{ID: uuid.New().String()}})
+	if !errors.Is(err, ErrVersionConflict) {
+		t.Fatalf("unexpected error type, expected conflict got %v", err)
+	}
+	parent.End()
+
+	spans := rec.Named("account.update")
+	if len(spans) != 1 {
+		t.Fatalf("unexpected operation spans, expected 1 got %d", len(spans))
+	}
+
+	s := spans[0]
+	if s.Parent.SpanID != parent.SpanContext().SpanID {
+		t.Error("operation span is not a caller span child")
+	}
+
+	if got := s.Attributes["finn.operation"]; got != opUpdate {
+		t.Errorf("unexpected operation attribute, got %v", got)
+	}
+
+	if got := s.Attributes["finn.status_class"]; got != "4xx" {
+		t.Errorf("unexpected status class attribute, got %v", got)
+	}
+
+	if got := s.Attributes["finn.error_kind"]; got != "conflict" {
+		t.Errorf("unexpected error kind attribute, got %v", got)
+	}
+
+	if len(s.Errors) != 1 || !errors.Is(s.Errors[0], ErrVersionConflict) {
+		t.Errorf("unexpected recorded errors, got %v", s.Errors)
+	}
+}
+
+func TestErrorKindAndStatusClass(t *testing.T) {
+	cases := []struct {
+		err   error
+		kind  string
+		class string
+	}{
+		{nil, "none", "2xx"},
+		{fmt.Errorf("wrapped, error %w", ErrVersionConflict), "conflict", "4xx"},
+		{&client.APIError{StatusCode: http.StatusServiceUnavailable}, "server", "5xx"},
+		{&client.APIError{StatusCode: http.StatusTooManyRequests}, "throttled", "4xx"},
+		{&client.RequestError{Err: context.DeadlineExceeded}, "timeout", "none"},
+		{&client.RequestError{Err: fmt.Errorf("connection refused")}, "transport", "none"},
+		{client.ErrCircuitOpen, "circuit_open", "none"},
+		{&client.DecodeError{StatusCode: http.StatusOK, Err: fmt.Errorf("unexpected EOF")}, "decode", "2xx"},
+		{&ValidationError{}, "validation", "none"},
+	}
+
+	for _, c := range cases {
+		if got := errorKind(c.err); got != c.kind {
+			t.Errorf("unexpected error kind for %v, expected %s got %s", c.err, c.kind, got)
+		}
+
+		if got := statusClass(c.err); got != c.class {
+			t.Errorf("unexpected status class for %v, expected %s got %s", c.err, c.class, got)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package redact
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+// Mask replaces redacted values
+const Mask = "[REDACTED]"
+
+// JSON masks string values on dotted json paths, as data.attributes.iban, arrays are traversed
+// transparently and * matches any key, matched objects and arrays keep their shape with all their
+// string leaves masked, so redacted documents can still be decoded, non json bodies are returned as they are
+func JSON(body []byte, paths []string) []byte {
+	if len(body) == 0 || len(paths) == 0 {
+		return body
+	}
+
+	var doc interface{}
+	if err := json.Unmarshal(body, &doc); err != nil {
+		return body
+	}
+
+	for _, p := range paths {
+		doc = mask(doc, strings.Split(p, "."))
+	}
+
+	raw, err := json.Marshal(doc)
+	if err != nil {
+		return body
+	}
+
+	return raw
+}
+
+// Header returns a header copy with masked values on names
+func Header(h http.Header, names []string) http.Header {
+	c := h.Clone()
+	for _, n := range names {
+		if c.Get(n) != "" {
+			c.Set(n, Mask)
+		}
+	}
+
+	return c
+}
+
+// mask replaces node values matching path segments
+func mask(node interface{}, path []string) interface{} {
+	if len(path) == 0 {
+		return leaves(node)
+	}
+
+	switch n := node.(type) {
+	case []interface{}:
+		for i := range n {
+			n[i] = mask(n[i], path)
+		}
+	case map[string]interface{}:
+		for k, v := range n {
+			if path[0] == "*" || path[0] == k {
+				n[k] = mask(v, path[1:])
+			}
+		}
+	}
+
+	return node
+}
+
+// leaves masks every string leaf
+func leaves(node interface{}) interface{} {
+	switch n := node.(type) {
+	case string:
+		return Mask
+	case []interface{}:
+		for i := range n {
+			n[i] = leaves(n[i])
+		}
+	case map[string]interface{}:
+		for k, v := range n {
+			n[k] = leaves(v)
+		}
+	}
+
+	return node
+}
@@ -0,0 +1,62 @@
+package redact
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestJSONMasksPathsTraversingArrays(t *testing.T) {
+	raw := []byte(`{"data": [{"id": "1", "attributes": {"iban": "GB16NWBK40030041426819", "country": "GB"}}, {"id": "2", "attributes": {"country": "ES"}}]}`)
+
+	got := string(JSON(raw, []string{"data.attributes.iban", "data.attributes.missing"}))
+	want := `{"data":[{"attributes":{"country":"GB","iban":"[REDACTED]"},"id":"1"},{"attributes":{"country":"ES"},"id":"2"}]}`
+	if got != want {
+		t.Errorf("redacted json does not match, expected %s got %s", want, got)
+	}
+}
+
+func TestJSONMasksWholeObjectsAndWildcards(t *testing.T) {
+	raw := []byte(`{"data": {"attributes": {"private_identification": {"birth_date": "2017-07-23"}, "name": ["Samantha Holder"]}}}`)
+
+	got := string(JSON(raw, []string{"data.attributes.private_identification", "data.*.name"}))
+	if strings.Contains(got, "2017-07-23") || strings.Contains(got, "Samantha") {
+		t.Errorf("unexpected unredacted content, got %s", got)
+	}
+}
+
+func TestJSONKeepsMaskedNodesShape(t *testing.T) {
+	raw := []byte(`{"actors": [{"name": ["Jeff Page"], "age": 50}], "joint": false}`)
+
+	got := string(JSON(raw, []string{"actors", "joint"}))
+	want := `{"actors":[{"age":50,"name":["[REDACTED]"]}],"joint":false}`
+	if got != want {
+		t.Errorf("redacted json does not match, expected %s got %s", want, got)
+	}
+}
+
+func TestJSONKeepsNonJSONBodies(t *testing.T) {
+	raw := []byte("plain text")
+	if got := string(JSON(raw, []string{"data"})); got != "plain text" {
+		t.Errorf("unexpected body, got %s", got)
+	}
+}
+
+func TestHeaderMasksValuesOnCopy(t *testing.T) {
+	h := make(http.Header)
+	h.Set("Authorization", "Bearer fakeToken")
+	h.Set("Accept", "application/json")
+
+	c := Header(h, []string{"Authorization", "Signature"})
+	if got := c.Get("Authorization"); got != Mask {
+		t.Errorf("authorization header not redacted, got %s", got)
+	}
+
+	if got := c.Get("Signature"); got != "" {
+		t.Errorf("unexpected signature header, got %s", got)
+	}
+
+	if got := h.Get("Authorization"); got != "Bearer fakeToken" {
+		t.Errorf("original header has been mutated, got %s", got)
+	}
+}
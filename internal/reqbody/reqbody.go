@@ -0,0 +1,24 @@
+// Package reqbody reads request bodies without consuming them
+package reqbody
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+)
+
+// Read reads request body restoring it for further reads
+func Read(req *http.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+
+	body, err := ioutil.ReadAll(req.Body)
+	_ = req.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	req.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	return body, nil
+}
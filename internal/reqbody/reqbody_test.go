@@ -0,0 +1,40 @@
+package reqbody
+
+import (
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestReadRestoresBody(t *testing.T) {
+	req, err := http.NewRequest(http.MethodPost, "http://fake/v1/organisation/accounts", strings.NewReader(`{"data": {}}`))
+	if err != nil {
+		t.Fatalf("unexpected error creating request, error %v", err)
+	}
+
+	body, err := Read(req)
+	if err != nil {
+		t.Fatalf("unexpected error reading body, error %v", err)
+	}
+
+	again, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		t.Fatalf("unexpected error reading restored body, error %v", err)
+	}
+
+	if string(body) != `{"data": {}}` || string(again) != string(body) {
+		t.Errorf("body not restored, got %s and %s", body, again)
+	}
+}
+
+func TestReadWithoutBody(t *testing.T) {
+	req, err := http.NewRequest(http.MethodGet, "http://fake/v1/organisation/accounts", nil)
+	if err != nil {
+		t.Fatalf("unexpected error creating request, error %v", err)
+	}
+
+	if body, err := Read(req); body != nil || err != nil {
+		t.Errorf("unexpected body %s error %v", body, err)
+	}
+}
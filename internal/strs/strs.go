@@ -0,0 +1,13 @@
+// Package strs holds string slice helpers
+package strs
+
+// Contains checks if v is on values
+func Contains(values []string, v string) bool {
+	for _, val := range values {
+		if val == v {
+			return true
+		}
+	}
+
+	return false
+}
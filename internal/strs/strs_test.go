@@ -0,0 +1,15 @@
+package strs
+
+import "testing"
+
+func TestContains(t *testing.T) {
+	values := []string{"date", "digest"}
+
+	if !Contains(values, "digest") {
+		t.Error("expected digest to be found")
+	}
+
+	if Contains(values, "host") || Contains(nil, "date") {
+		t.Error("unexpected value found")
+	}
+}
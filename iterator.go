@@ -0,0 +1,124 @@
+package finn
+
+import (
+	"context"
+)
+
+// Iterator walks all account list pages following JSON:API next links,
+// accounts are yielded one at a time and pages are fetched on demand
+type Iterator struct {
+	ctx      context.Context
+	client   *APIClient
+	next     string
+	page     []*AccoundData
+	current  *AccoundData
+	err      error
+	prefetch bool
+	pending  chan *pageResult
+}
+
+// pageResult holds an asynchronously fetched page
+type pageResult struct {
+	list *AccountList
+	err  error
+}
+
+// newIterator instantiates an iterator starting on uri page
+func newIterator(ctx context.Context, client *APIClient, uri string) *Iterator {
+	return &Iterator{
+		ctx:    ctx,
+		client: client,
+		next:   uri,
+	}
+}
+
+// WithPrefetch fetches next page concurrently while current one is consumed
+func (it *Iterator) WithPrefetch() *Iterator {
+	it.prefetch = true
+
+	return it
+}
+
+// Next advances to next account, returns false when all pages are consumed,
+// context gets cancelled or an error happens, check Err to tell them apart
+func (it *Iterator) Next() bool {
+	if it.err != nil {
+		return false
+	}
+
+	if err := it.ctx.Err(); err != nil {
+		it.err = err
+		return false
+	}
+
+	for len(it.page) == 0 {
+		if it.next == "" && it.pending == nil {
+			it.current = nil
+			return false
+		}
+
+		list, err := it.fetch()
+		if err != nil {
+			it.err = err
+			it.current = nil
+			return false
+		}
+
+		it.page = list.Accounts
+		it.follow(list)
+	}
+
+	it.current = it.page[0]
+	it.page = it.page[1:]
+
+	return true
+}
+
+// Account returns current account
+func (it *Iterator) Account() *AccoundData {
+	return it.current
+}
+
+// Err returns the error that stopped iteration, if any
+func (it *Iterator) Err() error {
+	return it.err
+}
+
+// fetch gets next page, from prefetched results when available
+func (it *Iterator) fetch() (*AccountList, error) {
+	if it.pending == nil {
+		uri := it.next
+		it.next = ""
+
+		return it.client.list(it.ctx, uri)
+	}
+
+	pending := it.pending
+	it.pending = nil
+
+	select {
+	case <-it.ctx.Done():
+		return nil, it.ctx.Err()
+	case res := <-pending:
+		return res.list, res.err
+	}
+}
+
+// follow sets next page from list links, starting its fetch on prefetch mode
+func (it *Iterator) follow(list *AccountList) {
+	if list.Links == nil || list.Links.Next == "" || list.Links.Next == list.Links.Self {
+		return
+	}
+
+	if !it.prefetch {
+		it.next = list.Links.Next
+		return
+	}
+
+	pending := make(chan *pageResult, 1)
+	go func(uri string) {
+		l, err := it.client.list(it.ctx, uri)
+		pending <- &pageResult{list: l, err: err}
+	}(list.Links.Next)
+	it.pending = pending
+}
@@ -0,0 +1,159 @@
+package finn
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+
+	client "github.com/marcosQuesada/finn/http"
+)
+
+func TestIteratorYieldsAccountsFromAllPages(t *testing.T) {
+	for _, prefetch := range []bool{false, true} {
+		h := newPagedHTTPClient(t, 3, 4)
+		api := NewAPIClient(h)
+
+		it := api.ListAll(context.Background(), NewPagination(0, 4))
+		if prefetch {
+			it = it.WithPrefetch()
+		}
+
+		var ids []string
+		for it.Next() {
+			ids = append(ids, it.Account().ID)
+		}
+
+		if err := it.Err(); err != nil {
+			t.Fatalf("unexpected iteration error, error %v", err)
+		}
+
+		if got, want := len(ids), 12; got != want {
+			t.Fatalf("unexpected accounts size with prefetch %t, expected %d got %d", prefetch, want, got)
+		}
+
+		for i, id := range ids {
+			if want := fmt.Sprintf("fakeUserID%d", i); id != want {
+				t.Errorf("unexpected account order, expected %s got %s", want, id)
+			}
+		}
+
+		if got, want := h.requests(), 3; got != want {
+			t.Errorf("unexpected page requests, expected %d got %d", want, got)
+		}
+	}
+}
+
+func TestIteratorStopsOnContextCancellation(t *testing.T) {
+	h := newPagedHTTPClient(t, 3, 4)
+	api := NewAPIClient(h)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	it := api.ListAll(ctx, NewPagination(0, 4))
+
+	count := 0
+	for it.Next() {
+		count++
+		if count == 2 {
+			cancel()
+		}
+	}
+
+	if !errors.Is(it.Err(), context.Canceled) {
+		t.Fatalf("unexpected iteration error, expected canceled got %v", it.Err())
+	}
+
+	if got, want := count, 2; got != want {
+		t.Errorf("unexpected yielded accounts, expected %d got %d", want, got)
+	}
+}
+
+func TestIteratorReturnsPageErrors(t *testing.T) {
+	h := &fakeHTTPClient{
+		err: client.ErrInternalServer,
+	}
+	api := NewAPIClient(h)
+
+	it := api.ListAll(context.Background(), NewPagination(0, 4))
+	if it.Next() {
+		t.Fatal("unexpected account on failed page")
+	}
+
+	if !errors.Is(it.Err(), client.ErrInternalServer) {
+		t.Errorf("unexpected iteration error, got %v", it.Err())
+	}
+}
+
+// pagedHTTPClient serves account list pages by uri, following api link format
+type pagedHTTPClient struct {
+	mutex sync.Mutex
+	pages map[string][]byte
+	calls int
+}
+
+func newPagedHTTPClient(t *testing.T, pages, size int) *pagedHTTPClient {
+	h := &pagedHTTPClient{pages: make(map[string][]byte)}
+	uri := func(page int) string {
+		return fmt.Sprintf("%s/%s?%s", apVersion, path, NewPagination(page, size).QueryString())
+	}
+
+	for p := 0; p < pages; p++ {
+		list := &AccountList{
+			Links: &LinkList{
+				First: uri(0),
+				Last:  uri(pages - 1),
+				Self:  uri(p),
+			},
+		}
+		if p > 0 {
+			list.Links.Prev = uri(p - 1)
+		}
+		if p < pages-1 {
+			list.Links.Next = uri(p + 1)
+		}
+
+		for i := 0; i < size; i++ {
+			list.Accounts = append(list.Accounts, &AccoundData{
+				Type: "accounts",
+				ID:   fmt.Sprintf("fakeUserID%d", p*size+i),
+			})
+		}
+
+		raw, err := json.Marshal(list)
+		if err != nil {
+			t.Fatalf("unexpected error marshalling account list, error %v", err)
+		}
+		h.pages[uri(p)] = raw
+	}
+
+	return h
+}
+
+func (p *pagedHTTPClient) Do(ctx context.Context, req *http.Request, v interface{}) (*http.Response, error) {
+	p.mutex.Lock()
+	p.calls++
+	body, ok := p.pages[req.URL.String()]
+	p.mutex.Unlock()
+
+	if !ok {
+		return (&fakeHTTPClient{statusCode: http.StatusNotFound, err: client.ErrContentNotFound}).Do(ctx, req, v)
+	}
+
+	return (&fakeHTTPClient{statusCode: http.StatusOK, body: body}).Do(ctx, req, v)
+}
+
+func (p *pagedHTTPClient) CreateRequest(method, url string, body interface{}) (*http.Request, error) {
+	return http.NewRequest(method, url, nil)
+}
+
+func (p *pagedHTTPClient) requests() int {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	return p.calls
+}
@@ -0,0 +1,179 @@
+package metrics
+
+import (
+	"sort"
+	"strings"
+	"sync"
+)
+
+const (
+	kindCounter   = "counter"
+	kindGauge     = "gauge"
+	kindHistogram = "histogram"
+)
+
+// Memory is an in-memory Registry, values can be read back on tests or exposed through Handler
+type Memory struct {
+	mu       sync.Mutex
+	families map[string]*family
+}
+
+// family holds an instrument series by label values
+type family struct {
+	registry *Memory
+	name     string
+	help     string
+	kind     string
+	labels   []string
+	buckets  []float64
+	series   map[string]*series
+}
+
+// series holds a label values combination state
+type series struct {
+	values []string
+	value  float64
+	counts []uint64
+	count  uint64
+	sum    float64
+}
+
+// NewMemory instantiates an in-memory registry
+func NewMemory() *Memory {
+	return &Memory{
+		families: make(map[string]*family),
+	}
+}
+
+// Counter registers a counter
+func (m *Memory) Counter(name, help string, labels ...string) Counter {
+	return m.register(name, help, kindCounter, nil, labels)
+}
+
+// Gauge registers a gauge
+func (m *Memory) Gauge(name, help string, labels ...string) Gauge {
+	return m.register(name, help, kindGauge, nil, labels)
+}
+
+// Histogram registers a histogram, DefaultBuckets are used when buckets are empty
+func (m *Memory) Histogram(name, help string, buckets []float64, labels ...string) Histogram {
+	if len(buckets) == 0 {
+		buckets = DefaultBuckets
+	}
+
+	b := append([]float64(nil), buckets...)
+	sort.Float64s(b)
+
+	return &histogram{m.register(name, help, kindHistogram, b, labels)}
+}
+
+// Value returns counter or gauge value, or histogram observations count, for label values
+func (m *Memory) Value(name string, labelValues ...string) float64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	f, ok := m.families[name]
+	if !ok {
+		return 0
+	}
+
+	s, ok := f.series[key(labelValues)]
+	if !ok {
+		return 0
+	}
+
+	if f.kind == kindHistogram {
+		return float64(s.count)
+	}
+
+	return s.value
+}
+
+// Sum returns histogram observations sum for label values
+func (m *Memory) Sum(name string, labelValues ...string) float64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	f, ok := m.families[name]
+	if !ok {
+		return 0
+	}
+
+	s, ok := f.series[key(labelValues)]
+	if !ok {
+		return 0
+	}
+
+	return s.sum
+}
+
+// register returns named family, creating it when missing
+func (m *Memory) register(name, help, kind string, buckets []float64, labels []string) *family {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if f, ok := m.families[name]; ok {
+		return f
+	}
+
+	f := &family{
+		registry: m,
+		name:     name,
+		help:     help,
+		kind:     kind,
+		labels:   labels,
+		buckets:  buckets,
+		series:   make(map[string]*series),
+	}
+	m.families[name] = f
+
+	return f
+}
+
+// Add increments counter or gauge series
+func (f *family) Add(v float64, labelValues ...string) {
+	f.registry.mu.Lock()
+	defer f.registry.mu.Unlock()
+
+	f.get(labelValues).value += v
+}
+
+// get returns label values series, missing values are empty and extra ones are dropped
+func (f *family) get(labelValues []string) *series {
+	values := make([]string, len(f.labels))
+	copy(values, labelValues)
+
+	k := key(values)
+	s, ok := f.series[k]
+	if !ok {
+		s = &series{values: values, counts: make([]uint64, len(f.buckets))}
+		f.series[k] = s
+	}
+
+	return s
+}
+
+// histogram exposes family as a Histogram
+type histogram struct {
+	*family
+}
+
+// Observe records an observation on matching buckets
+func (h *histogram) Observe(v float64, labelValues ...string) {
+	h.registry.mu.Lock()
+	defer h.registry.mu.Unlock()
+
+	s := h.get(labelValues)
+	s.count++
+	s.sum += v
+	for i, b := range h.buckets {
+		if v <= b {
+			s.counts[i]++
+		}
+	}
+}
+
+// key joins label values as series key
+func key(values []string) string {
+	return strings.Join(values, "\xff")
+}
@@ -0,0 +1,79 @@
+package metrics
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestMemoryCounterAccumulatesBySeries(t *testing.T) {
+	m := NewMemory()
+	c := m.Counter("requests_total", "Requests", "operation", "status")
+
+	c.Add(1, "fetch", "2xx")
+	c.Add(2, "fetch", "2xx")
+	c.Add(1, "fetch", "4xx")
+
+	if got, want := m.Value("requests_total", "fetch", "2xx"), 3.0; got != want {
+		t.Errorf("unexpected counter value, expected %v got %v", want, got)
+	}
+
+	if got, want := m.Value("requests_total", "fetch", "4xx"), 1.0; got != want {
+		t.Errorf("unexpected counter value, expected %v got %v", want, got)
+	}
+
+	if got := m.Value("requests_total", "create", "2xx"); got != 0 {
+		t.Errorf("unexpected counter value on unknown series, got %v", got)
+	}
+}
+
+func TestMemoryRegisteringAKnownNameReturnsSameInstrument(t *testing.T) {
+	m := NewMemory()
+	m.Gauge("in_flight", "In flight", "operation").Add(1, "list")
+	m.Gauge("in_flight", "In flight", "operation").Add(1, "list")
+
+	if got, want := m.Value("in_flight", "list"), 2.0; got != want {
+		t.Errorf("unexpected gauge value, expected %v got %v", want, got)
+	}
+}
+
+func TestMemoryHistogramObservesCountAndSum(t *testing.T) {
+	m := NewMemory()
+	h := m.Histogram("latency_seconds", "Latency", []float64{1, .1}, "operation")
+
+	h.Observe(.05, "fetch")
+	h.Observe(.5, "fetch")
+	h.Observe(2, "fetch")
+
+	if got, want := m.Value("latency_seconds", "fetch"), 3.0; got != want {
+		t.Errorf("unexpected observations count, expected %v got %v", want, got)
+	}
+
+	if got, want := m.Sum("latency_seconds", "fetch"), 2.55; got != want {
+		t.Errorf("unexpected observations sum, expected %v got %v", want, got)
+	}
+}
+
+func TestMemoryIsSafeForConcurrentUse(t *testing.T) {
+	m := NewMemory()
+	c := m.Counter("requests_total", "Requests")
+	h := m.Histogram("latency_seconds", "Latency", nil)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c.Add(1)
+			h.Observe(.01)
+		}()
+	}
+	wg.Wait()
+
+	if got, want := m.Value("requests_total"), 50.0; got != want {
+		t.Errorf("unexpected counter value, expected %v got %v", want, got)
+	}
+
+	if got, want := m.Value("latency_seconds"), 50.0; got != want {
+		t.Errorf("unexpected observations count, expected %v got %v", want, got)
+	}
+}
@@ -0,0 +1,28 @@
+// Package metrics defines dependency free instrumentation primitives, an in-memory Registry
+// and a Prometheus text format exposition handler
+package metrics
+
+// DefaultBuckets are latency histogram upper bounds, in seconds
+var DefaultBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// Counter is a monotonically increasing value, label values follow registered label names order
+type Counter interface {
+	Add(v float64, labelValues ...string)
+}
+
+// Gauge is a value that goes up and down, as in-flight requests
+type Gauge interface {
+	Add(v float64, labelValues ...string)
+}
+
+// Histogram samples observations on buckets
+type Histogram interface {
+	Observe(v float64, labelValues ...string)
+}
+
+// Registry creates named instruments, registering an already known name returns the same instrument
+type Registry interface {
+	Counter(name, help string, labels ...string) Counter
+	Gauge(name, help string, labels ...string) Gauge
+	Histogram(name, help string, buckets []float64, labels ...string) Histogram
+}
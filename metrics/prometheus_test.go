@@ -0,0 +1,53 @@
+package metrics
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHandlerExposesPrometheusTextFormat(t *testing.T) {
+	m := NewMemory()
+	m.Counter("finn_requests_total", "Requests\nsent", "operation").Add(2, `fe"tch`)
+	m.Gauge("finn_in_flight", "").Add(1)
+	h := m.Histogram("finn_latency_seconds", "Latency", []float64{.1, 1}, "operation")
+	h.Observe(.05, "list")
+	h.Observe(.5, "list")
+
+	srv := httptest.NewServer(Handler(m))
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL)
+	if err != nil {
+		t.Fatalf("unexpected error scraping metrics, error %v", err)
+	}
+	defer resp.Body.Close()
+
+	if ct := resp.Header.Get("Content-Type"); !strings.HasPrefix(ct, "text/plain; version=0.0.4") {
+		t.Errorf("unexpected content type, got %s", ct)
+	}
+
+	raw, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("unexpected error reading metrics, error %v", err)
+	}
+
+	expected := `# TYPE finn_in_flight gauge
+finn_in_flight 1
+# HELP finn_latency_seconds Latency
+# TYPE finn_latency_seconds histogram
+finn_latency_seconds_bucket{operation="list",le="0.1"} 1
+finn_latency_seconds_bucket{operation="list",le="1"} 2
+finn_latency_seconds_bucket{operation="list",le="+Inf"} 2
+finn_latency_seconds_sum{operation="list"} 0.55
+finn_latency_seconds_count{operation="list"} 2
+# HELP finn_requests_total Requests\nsent
+# TYPE finn_requests_total counter
+finn_requests_total{operation="fe\"tch"} 2
+`
+	if got := string(raw); got != expected {
+		t.Errorf("unexpected exposition, expected\n%s\ngot\n%s", expected, got)
+	}
+}
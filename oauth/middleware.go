@@ -0,0 +1,71 @@
+package oauth
+
+import (
+	"io"
+	"io/ioutil"
+	"net/http"
+
+	client "github.com/marcosQuesada/finn/http"
+)
+
+// Middleware authorizes each request attempt with a token from src, a 401 response forces one token
+// refresh and the request is replayed, requests whose body cannot be rewound are not replayed
+func Middleware(src TokenSource) client.Middleware {
+	return func(next client.RoundTripFunc) client.RoundTripFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			ctx := req.Context()
+			tok, err := src.Token(ctx)
+			if err != nil {
+				return nil, err
+			}
+
+			replay, err := clone(req)
+			if err != nil {
+				return nil, err
+			}
+
+			req.Header.Set("Authorization", tok.authorization())
+			resp, err := next(req)
+			if err != nil || resp.StatusCode != http.StatusUnauthorized || replay == nil {
+				return resp, err
+			}
+
+			fresh, err := src.Refresh(ctx, tok)
+			if err != nil {
+				return resp, nil
+			}
+			discard(resp)
+
+			replay.Header.Set("Authorization", fresh.authorization())
+
+			return next(replay)
+		}
+	}
+}
+
+// clone copies request with a rewound body, nil is returned when body cannot be rewound
+func clone(req *http.Request) (*http.Request, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return req.Clone(req.Context()), nil
+	}
+
+	if req.GetBody == nil {
+		return nil, nil
+	}
+
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, err
+	}
+
+	r := req.Clone(req.Context())
+	r.Body = body
+
+	return r, nil
+}
+
+// discard drains and closes response body so the connection can be reused
+func discard(resp *http.Response) {
+	_, _ = io.Copy(ioutil.Discard, resp.Body)
+	_ = resp.Body.Close()
+}
@@ -0,0 +1,121 @@
+package oauth
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	client "github.com/marcosQuesada/finn/http"
+)
+
+// protectedServer accepts requests carrying accepted bearer token only
+type protectedServer struct {
+	*httptest.Server
+	mu       sync.Mutex
+	accepted string
+	bodies   []string
+}
+
+func newProtectedServer(accepted string) *protectedServer {
+	s := &protectedServer{accepted: accepted}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		if r.Header.Get("Authorization") != "Bearer "+s.accepted {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		body, _ := ioutil.ReadAll(r.Body)
+		s.bodies = append(s.bodies, string(body))
+		w.WriteHeader(http.StatusCreated)
+	}))
+
+	return s
+}
+
+func TestMiddleware_UnauthorizedForcesRefreshAndReplay(t *testing.T) {
+	e := newTokenEndpoint(3600)
+	defer e.Close()
+
+	srv := newProtectedServer("token-2")
+	defer srv.Close()
+
+	src := NewClientCredentials(e.config())
+	if _, err := src.Token(context.Background()); err != nil {
+		t.Fatalf("unexpected error getting token, error %v", err)
+	}
+
+	u, _ := url.Parse(srv.URL + "/")
+	c := client.NewClientWithUrl(u, client.WithMiddleware(Middleware(src)))
+	req, err := c.CreateRequest(http.MethodPost, "v1/organisation/accounts", map[string]string{"id": "fakeID"})
+	if err != nil {
+		t.Fatalf("unexpected error creating request, error %v", err)
+	}
+
+	resp, err := c.Do(context.Background(), req, nil)
+	if err != nil {
+		t.Fatalf("unexpected error on replayed request, error %v", err)
+	}
+
+	if resp.StatusCode != http.StatusCreated {
+		t.Errorf("unexpected status code, expected 201 got %d", resp.StatusCode)
+	}
+
+	if len(srv.bodies) != 1 || srv.bodies[0] != `{"id":"fakeID"}` {
+		t.Errorf("replayed body does not match, got %v", srv.bodies)
+	}
+}
+
+func TestMiddleware_PersistentUnauthorizedRefreshesOnce(t *testing.T) {
+	e := newTokenEndpoint(3600)
+	defer e.Close()
+
+	srv := newProtectedServer("revoked")
+	defer srv.Close()
+
+	u, _ := url.Parse(srv.URL + "/")
+	c := client.NewClientWithUrl(u, client.WithMiddleware(Middleware(NewClientCredentials(e.config()))))
+	req, err := c.CreateRequest(http.MethodGet, "v1/organisation/accounts", nil)
+	if err != nil {
+		t.Fatalf("unexpected error creating request, error %v", err)
+	}
+
+	_, err = c.Do(context.Background(), req, nil)
+	if !errors.Is(err, client.ErrUnauthorized) {
+		t.Errorf("unexpected error type, expected unauthorized got %v", err)
+	}
+
+	if got := atomic.LoadInt32(&e.issued); got != 2 {
+		t.Errorf("token requests do not match, expected 2 got %d", got)
+	}
+}
+
+func TestMiddleware_TokenErrorsFailRequest(t *testing.T) {
+	e := newTokenEndpoint(3600)
+	defer e.Close()
+
+	srv := newProtectedServer("token-1")
+	defer srv.Close()
+
+	cfg := e.config()
+	cfg.ClientID = "unknown"
+	u, _ := url.Parse(srv.URL + "/")
+	c := client.NewClientWithUrl(u, client.WithMiddleware(Middleware(NewClientCredentials(cfg))))
+	req, err := c.CreateRequest(http.MethodGet, "v1/organisation/accounts", nil)
+	if err != nil {
+		t.Fatalf("unexpected error creating request, error %v", err)
+	}
+
+	_, err = c.Do(context.Background(), req, nil)
+	if !errors.Is(err, ErrTokenRequest) {
+		t.Errorf("unexpected error type, expected token request error got %v", err)
+	}
+}
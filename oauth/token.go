@@ -0,0 +1,225 @@
+package oauth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const defaultExpiryDelta = 10 * time.Second
+const defaultTimeout = 10 * time.Second
+const maxTokenBodySize = 1 << 20
+
+// ErrTokenRequest happens when token endpoint refuses or fails to issue a token
+var ErrTokenRequest = errors.New("token request failed")
+
+// Token defines an issued access token
+type Token struct {
+	AccessToken string
+	TokenType   string
+	// Expiry is zero when token endpoint does not declare token lifetime
+	Expiry time.Time
+}
+
+// valid checks token is present and not expiring within delta
+func (t *Token) valid(now time.Time, delta time.Duration) bool {
+	if t == nil || t.AccessToken == "" {
+		return false
+	}
+
+	return t.Expiry.IsZero() || now.Add(delta).Before(t.Expiry)
+}
+
+// authorization builds Authorization header value
+func (t *Token) authorization() string {
+	typ := t.TokenType
+	if typ == "" || strings.EqualFold(typ, "bearer") {
+		typ = "Bearer"
+	}
+
+	return typ + " " + t.AccessToken
+}
+
+// TokenSource provides access tokens, Refresh forces renewal unless stale token was already replaced
+type TokenSource interface {
+	Token(ctx context.Context) (*Token, error)
+	Refresh(ctx context.Context, stale *Token) (*Token, error)
+}
+
+// Config defines client credentials grant parameters
+type Config struct {
+	TokenURL     string
+	ClientID     string
+	ClientSecret string
+	Scopes       []string
+	// HTTPClient executes token requests, http.DefaultClient when nil
+	HTTPClient *http.Client
+	// ExpiryDelta renews tokens this long before they expire, 10 seconds when zero
+	ExpiryDelta time.Duration
+	// Timeout bounds each token request, 10 seconds when zero
+	Timeout time.Duration
+}
+
+// ClientCredentials is a TokenSource running the OAuth2 client credentials grant, tokens are cached
+// until just before they expire and concurrent renewals share a single token request
+type ClientCredentials struct {
+	cfg      Config
+	mu       sync.Mutex
+	token    *Token
+	inflight *call
+	now      func() time.Time
+}
+
+// call holds an in flight token request
+type call struct {
+	done  chan struct{}
+	token *Token
+	err   error
+}
+
+// NewClientCredentials instantiates a client credentials token source
+func NewClientCredentials(cfg Config) *ClientCredentials {
+	if cfg.HTTPClient == nil {
+		cfg.HTTPClient = http.DefaultClient
+	}
+
+	if cfg.ExpiryDelta == 0 {
+		cfg.ExpiryDelta = defaultExpiryDelta
+	}
+
+	if cfg.Timeout == 0 {
+		cfg.Timeout = defaultTimeout
+	}
+
+	return &ClientCredentials{
+		cfg: cfg,
+		now: time.Now,
+	}
+}
+
+// Token returns cached token, requesting a new one when missing or about to expire
+func (c *ClientCredentials) Token(ctx context.Context) (*Token, error) {
+	return c.get(ctx, nil, false)
+}
+
+// Refresh requests a new token unless stale token has already been replaced by another caller
+func (c *ClientCredentials) Refresh(ctx context.Context, stale *Token) (*Token, error) {
+	return c.get(ctx, stale, true)
+}
+
+// get returns a usable token, joining in flight token request when there is one, token requests are
+// detached from caller context so a cancelled caller does not fail the ones waiting on it
+func (c *ClientCredentials) get(ctx context.Context, stale *Token, force bool) (*Token, error) {
+	c.mu.Lock()
+	if c.token.valid(c.now(), c.cfg.ExpiryDelta) && (!force || c.token != stale) {
+		t := c.token
+		c.mu.Unlock()
+
+		return t, nil
+	}
+
+	if c.inflight == nil {
+		c.inflight = &call{done: make(chan struct{})}
+		go c.renew(c.inflight)
+	}
+	cl := c.inflight
+	c.mu.Unlock()
+
+	select {
+	case <-cl.done:
+		return cl.token, cl.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// renew executes token request and publishes its result
+func (c *ClientCredentials) renew(cl *call) {
+	ctx, cancel := context.WithTimeout(context.Background(), c.cfg.Timeout)
+	defer cancel()
+
+	cl.token, cl.err = c.request(ctx)
+
+	c.mu.Lock()
+	if cl.err == nil {
+		c.token = cl.token
+	}
+	c.inflight = nil
+	c.mu.Unlock()
+
+	close(cl.done)
+}
+
+// tokenResponse defines token endpoint success and error payloads
+type tokenResponse struct {
+	AccessToken      string `json:"access_token"`
+	TokenType        string `json:"token_type"`
+	ExpiresIn        int64  `json:"expires_in"`
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+// request posts client credentials grant to token endpoint, client credentials are sent using
+// http basic authentication
+func (c *ClientCredentials) request(ctx context.Context) (*Token, error) {
+	form := url.Values{"grant_type": {"client_credentials"}}
+	if len(c.cfg.Scopes) > 0 {
+		form.Set("scope", strings.Join(c.cfg.Scopes, " "))
+	}
+
+	req, err := http.NewRequest(http.MethodPost, c.cfg.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	req.SetBasicAuth(url.QueryEscape(c.cfg.ClientID), url.QueryEscape(c.cfg.ClientSecret))
+
+	issued := c.now()
+	resp, err := c.cfg.HTTPClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("token endpoint %s, error %w", c.cfg.TokenURL, err)
+	}
+
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	body, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxTokenBodySize))
+	if err != nil {
+		return nil, fmt.Errorf("token endpoint %s, error %w", c.cfg.TokenURL, err)
+	}
+
+	tr := &tokenResponse{}
+	decodeErr := json.Unmarshal(body, tr)
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("status code %d %s %s, error %w", resp.StatusCode, tr.Error, tr.ErrorDescription, ErrTokenRequest)
+	}
+
+	if decodeErr != nil {
+		return nil, fmt.Errorf("unexpected token response %v, error %w", decodeErr, ErrTokenRequest)
+	}
+
+	if tr.AccessToken == "" {
+		return nil, fmt.Errorf("empty access token, error %w", ErrTokenRequest)
+	}
+
+	t := &Token{
+		AccessToken: tr.AccessToken,
+		TokenType:   tr.TokenType,
+	}
+
+	if tr.ExpiresIn > 0 {
+		t.Expiry = issued.Add(time.Duration(tr.ExpiresIn) * time.Second)
+	}
+
+	return t, nil
+}
@@ -0,0 +1,180 @@
+package oauth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// tokenEndpoint fakes a client credentials token endpoint issuing sequential tokens
+type tokenEndpoint struct {
+	*httptest.Server
+	issued    int32
+	expiresIn int
+	delay     time.Duration
+}
+
+func newTokenEndpoint(expiresIn int) *tokenEndpoint {
+	e := &tokenEndpoint{expiresIn: expiresIn}
+	e.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id, secret, ok := r.BasicAuth()
+		if !ok || id != "fakeClient" || secret != "fakeSecret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"error": "invalid_client", "error_description": "bad credentials"}`))
+			return
+		}
+
+		if r.PostFormValue("grant_type") != "client_credentials" {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"error": "unsupported_grant_type"}`))
+			return
+		}
+
+		time.Sleep(e.delay)
+		n := atomic.AddInt32(&e.issued, 1)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = fmt.Fprintf(w, `{"access_token": "token-%d", "token_type": "bearer", "expires_in": %d, "scope": "%s"}`,
+			n, e.expiresIn, r.PostFormValue("scope"))
+	}))
+
+	return e
+}
+
+func (e *tokenEndpoint) config() Config {
+	return Config{
+		TokenURL:     e.URL + "/oauth2/token",
+		ClientID:     "fakeClient",
+		ClientSecret: "fakeSecret",
+		Scopes:       []string{"accounts:read", "accounts:write"},
+	}
+}
+
+func TestClientCredentials_TokenIsCachedUntilExpiry(t *testing.T) {
+	e := newTokenEndpoint(3600)
+	defer e.Close()
+
+	now := time.Now()
+	src := NewClientCredentials(e.config())
+	src.now = func() time.Time { return now }
+
+	for i := 0; i < 3; i++ {
+		tok, err := src.Token(context.Background())
+		if err != nil {
+			t.Fatalf("unexpected error getting token, error %v", err)
+		}
+
+		if tok.AccessToken != "token-1" {
+			t.Errorf("unexpected token, expected token-1 got %s", tok.AccessToken)
+		}
+	}
+
+	now = now.Add(time.Hour - defaultExpiryDelta)
+	tok, err := src.Token(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error getting token, error %v", err)
+	}
+
+	if tok.AccessToken != "token-2" {
+		t.Errorf("expiring token not renewed, expected token-2 got %s", tok.AccessToken)
+	}
+
+	if got := atomic.LoadInt32(&e.issued); got != 2 {
+		t.Errorf("token requests do not match, expected 2 got %d", got)
+	}
+}
+
+func TestClientCredentials_ConcurrentCallersShareTokenRequest(t *testing.T) {
+	e := newTokenEndpoint(3600)
+	e.delay = 50 * time.Millisecond
+	defer e.Close()
+
+	src := NewClientCredentials(e.config())
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			tok, err := src.Token(context.Background())
+			if err != nil {
+				t.Errorf("unexpected error getting token, error %v", err)
+				return
+			}
+
+			if tok.AccessToken != "token-1" {
+				t.Errorf("unexpected token, expected token-1 got %s", tok.AccessToken)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := atomic.LoadInt32(&e.issued); got != 1 {
+		t.Errorf("token requests do not match, expected 1 got %d", got)
+	}
+}
+
+func TestClientCredentials_RefreshSkipsAlreadyReplacedTokens(t *testing.T) {
+	e := newTokenEndpoint(3600)
+	defer e.Close()
+
+	src := NewClientCredentials(e.config())
+	stale, err := src.Token(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error getting token, error %v", err)
+	}
+
+	fresh, err := src.Refresh(context.Background(), stale)
+	if err != nil {
+		t.Fatalf("unexpected error refreshing token, error %v", err)
+	}
+
+	again, err := src.Refresh(context.Background(), stale)
+	if err != nil {
+		t.Fatalf("unexpected error refreshing token, error %v", err)
+	}
+
+	if fresh.AccessToken != "token-2" || again.AccessToken != "token-2" {
+		t.Errorf("unexpected refreshed tokens, got %s and %s", fresh.AccessToken, again.AccessToken)
+	}
+}
+
+func TestClientCredentials_TokenEndpointErrors(t *testing.T) {
+	e := newTokenEndpoint(3600)
+	defer e.Close()
+
+	cfg := e.config()
+	cfg.ClientSecret = "wrongSecret"
+	_, err := NewClientCredentials(cfg).Token(context.Background())
+	if !errors.Is(err, ErrTokenRequest) {
+		t.Errorf("unexpected error type, expected token request error got %v", err)
+	}
+}
+
+func TestClientCredentials_CancelledCallerDoesNotWaitForTokenRequest(t *testing.T) {
+	e := newTokenEndpoint(3600)
+	e.delay = 200 * time.Millisecond
+	defer e.Close()
+
+	src := NewClientCredentials(e.config())
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	if _, err := src.Token(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("unexpected error type, expected deadline exceeded got %v", err)
+	}
+
+	tok, err := src.Token(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error getting token, error %v", err)
+	}
+
+	if tok.AccessToken != "token-1" {
+		t.Errorf("in flight token request not shared, expected token-1 got %s", tok.AccessToken)
+	}
+}
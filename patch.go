@@ -0,0 +1,83 @@
+package finn
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// patchDocument is a JSON:API partial account document, attributes hold changed fields only and
+// null values clear stored ones
+type patchDocument struct {
+	Data *patchData `json:"data"`
+}
+
+// patchData holds patched account identity, version and attributes
+type patchData struct {
+	Type       string                     `json:"type"`
+	ID         string                     `json:"id"`
+	Version    int                        `json:"version"`
+	Attributes map[string]json.RawMessage `json:"attributes,omitempty"`
+}
+
+// newPatchDocument builds a partial document with account attributes differing from base fields,
+// fields removed from base are sent as null, without base only non empty attributes are sent and
+// nothing is cleared
+func newPatchDocument(base map[string]json.RawMessage, d *AccoundData) (*patchDocument, error) {
+	fields, err := attributeFields(d.Attributes)
+	if err != nil {
+		return nil, err
+	}
+
+	partial := base == nil
+	if partial {
+		if base, err = attributeFields(&Attributes{}); err != nil {
+			return nil, err
+		}
+	}
+
+	changed := make(map[string]json.RawMessage)
+	for k, v := range fields {
+		if !bytes.Equal(base[k], v) {
+			changed[k] = v
+		}
+	}
+
+	for k := range base {
+		if _, ok := fields[k]; !ok && !partial {
+			changed[k] = json.RawMessage("null")
+		}
+	}
+
+	typ := d.Type
+	if typ == "" {
+		typ = "accounts"
+	}
+
+	return &patchDocument{
+		Data: &patchData{
+			Type:       typ,
+			ID:         d.ID,
+			Version:    d.Version,
+			Attributes: changed,
+		},
+	}, nil
+}
+
+// attributeFields returns attributes json encoded fields, nil attributes have no fields
+func attributeFields(a *Attributes) (map[string]json.RawMessage, error) {
+	fields := make(map[string]json.RawMessage)
+	if a == nil {
+		return fields, nil
+	}
+
+	raw, err := json.Marshal(a)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return nil, err
+	}
+
+	return fields, nil
+}
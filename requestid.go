@@ -0,0 +1,63 @@
+package finn
+
+import (
+	"errors"
+	"fmt"
+
+	client "github.com/marcosQuesada/finn/http"
+)
+
+// OperationError attaches operation name and request id to APIClient errors, every APIClient error is
+// returned as an *OperationError, so sentinel errors have to be matched through errors.Is and
+// error types through errors.As
+type OperationError struct {
+	Op        string
+	RequestID string
+	Err       error
+}
+
+// Error describes operation, request id and wrapped error
+func (e *OperationError) Error() string {
+	return fmt.Sprintf("account %s, request id %s, error %v", e.Op, e.RequestID, e.Err)
+}
+
+// Unwrap returns wrapped error
+func (e *OperationError) Unwrap() error {
+	return e.Err
+}
+
+// RequestID returns request id attached to err, empty when err holds none
+func RequestID(err error) string {
+	var opErr *OperationError
+	if errors.As(err, &opErr) {
+		return opErr.RequestID
+	}
+
+	var apiErr *client.APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.RequestID
+	}
+
+	var reqErr *client.RequestError
+	if errors.As(err, &reqErr) {
+		return reqErr.RequestID
+	}
+
+	var decodeErr *client.DecodeError
+	if errors.As(err, &decodeErr) {
+		return decodeErr.RequestID
+	}
+
+	return ""
+}
+
+// withOperation wraps err with operation and request id, errors already attached to an
+// operation, as those coming from nested operations, are returned as they are
+func withOperation(op, requestID string, err error) error {
+	var opErr *OperationError
+	if err == nil || errors.As(err, &opErr) {
+		return err
+	}
+
+	return &OperationError{Op: op, RequestID: requestID, Err: err}
+}
@@ -0,0 +1,92 @@
+package finn
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	client "github.com/marcosQuesada/finn/http"
+)
+
+func TestOperationsBindAGeneratedRequestID(t *testing.T) {
+	h := &fakeHTTPClient{statusCode: http.StatusNotFound, err: &client.APIError{StatusCode: http.StatusNotFound}}
+	api := NewAPIClient(h)
+
+	_, err := api.Fetch(context.Background(), uuid.New().String())
+	if !errors.Is(err, client.ErrContentNotFound) {
+		t.Fatalf("unexpected error type, got %v", err)
+	}
+
+	id := client.RequestIDFromContext(h.ctx)
+	if id == "" {
+		t.Fatal("request id not bound to request context")
+	}
+
+	if got := RequestID(err); got != id {
+		t.Errorf("unexpected error request id, expected %s got %s", id, got)
+	}
+
+	if !strings.Contains(err.Error(), id) {
+		t.Errorf("error message does not hold request id, got %s", err.Error())
+	}
+}
+
+func TestOperationsKeepContextRequestID(t *testing.T) {
+	h := &fakeHTTPClient{statusCode: http.StatusConflict, err: &client.APIError{StatusCode: http.StatusConflict}}
+	api := NewAPIClient(h)
+
+	ctx := client.ContextWithRequestID(context.Background(), "fakeRequestID")
+	err := api.Delete(ctx, uuid.New().String(), 0)
+	if !errors.Is(err, ErrVersionConflict) {
+		t.Fatalf("unexpected error type, got %v", err)
+	}
+
+	if got := client.RequestIDFromContext(h.ctx); got != "fakeRequestID" {
+		t.Errorf("unexpected request context id, got %s", got)
+	}
+
+	var opErr *OperationError
+	if !errors.As(err, &opErr) || opErr.Op != opDelete || opErr.RequestID != "fakeRequestID" {
+		t.Errorf("unexpected operation error, got %v", err)
+	}
+}
+
+func TestOperationsAttachRequestIDOnLocalErrors(t *testing.T) {
+	api := NewAPIClient(&fakeHTTPClient{})
+
+	_, err := api.Update(context.Background(), &Account{})
+	if !errors.Is(err, ErrInvalidAccount) {
+		t.Fatalf("unexpected error type, got %v", err)
+	}
+
+	if RequestID(err) == "" {
+		t.Error("request id not attached to error")
+	}
+}
+
+func TestCreateReplayLookupSharesRequestID(t *testing.T) {
+	h := &sequenceHTTPClient{
+		responses: []*fakeHTTPClient{
+			{statusCode: http.StatusConflict, err: &client.APIError{StatusCode: http.StatusConflict}},
+			{statusCode: http.StatusNotFound, err: &client.APIError{StatusCode: http.StatusNotFound}},
+		},
+	}
+	api := NewAPIClient(h)
+
+	_, err := api.Create(context.Background(), &Account{AccoundData: &AccoundData{ID: uuid.New().String()}})
+	if !errors.Is(err, client.ErrContentNotFound) {
+		t.Fatalf("unexpected error type, got %v", err)
+	}
+
+	create, fetch := client.RequestIDFromContext(h.responses[0].ctx), client.RequestIDFromContext(h.responses[1].ctx)
+	if create == "" || create != fetch {
+		t.Errorf("replay lookup request id does not match, expected %s got %s", create, fetch)
+	}
+
+	if strings.Count(err.Error(), create) != 1 {
+		t.Errorf("request id should appear once on error, got %s", err.Error())
+	}
+}
@@ -0,0 +1,78 @@
+package signature
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+)
+
+// ErrUnsupportedKey happens on keys other than RSA or Ed25519
+var ErrUnsupportedKey = errors.New("unsupported key")
+
+// LoadPrivateKey decodes a PEM encoded RSA (PKCS1 or PKCS8) or Ed25519 (PKCS8) private key
+func LoadPrivateKey(raw []byte) (crypto.Signer, error) {
+	block, _ := pem.Decode(raw)
+	if block == nil {
+		return nil, errors.New("no pem block found")
+	}
+
+	if block.Type == "RSA PRIVATE KEY" {
+		return x509.ParsePKCS1PrivateKey(block.Bytes)
+	}
+
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		return k, nil
+	case ed25519.PrivateKey:
+		return k, nil
+	}
+
+	return nil, fmt.Errorf("key type %T, error %w", key, ErrUnsupportedKey)
+}
+
+// LoadPublicKey decodes a PEM encoded RSA (PKCS1 or PKIX) or Ed25519 (PKIX) public key
+func LoadPublicKey(raw []byte) (crypto.PublicKey, error) {
+	block, _ := pem.Decode(raw)
+	if block == nil {
+		return nil, errors.New("no pem block found")
+	}
+
+	if block.Type == "RSA PUBLIC KEY" {
+		return x509.ParsePKCS1PublicKey(block.Bytes)
+	}
+
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+
+	switch k := key.(type) {
+	case *rsa.PublicKey:
+		return k, nil
+	case ed25519.PublicKey:
+		return k, nil
+	}
+
+	return nil, fmt.Errorf("key type %T, error %w", key, ErrUnsupportedKey)
+}
+
+// algorithmOf returns signature algorithm by key type
+func algorithmOf(key interface{}) (string, error) {
+	switch key.(type) {
+	case *rsa.PrivateKey, *rsa.PublicKey:
+		return AlgorithmRSASHA256, nil
+	case ed25519.PrivateKey, ed25519.PublicKey:
+		return AlgorithmEd25519, nil
+	}
+
+	return "", fmt.Errorf("key type %T, error %w", key, ErrUnsupportedKey)
+}
@@ -0,0 +1,209 @@
+package signature
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	client "github.com/marcosQuesada/finn/http"
+)
+
+func TestSigner_SignedRequestsAreVerified(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("unexpected error generating rsa key, error %v", err)
+	}
+
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("unexpected error generating ed25519 key, error %v", err)
+	}
+
+	for _, key := range []crypto.Signer{rsaKey, edKey} {
+		priv, pub := encodeKeys(t, key)
+		signingKey, err := LoadPrivateKey(priv)
+		if err != nil {
+			t.Fatalf("unexpected error loading private key, error %v", err)
+		}
+
+		publicKey, err := LoadPublicKey(pub)
+		if err != nil {
+			t.Fatalf("unexpected error loading public key, error %v", err)
+		}
+
+		signer, err := NewSigner("fakeKeyID", signingKey)
+		if err != nil {
+			t.Fatalf("unexpected error creating signer, error %v", err)
+		}
+
+		var body []byte
+		v := NewVerifier(StaticKeys(map[string]crypto.PublicKey{"fakeKeyID": publicKey}))
+		srv := httptest.NewServer(v.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ = ioutil.ReadAll(r.Body)
+			w.WriteHeader(http.StatusCreated)
+		})))
+
+		u, _ := url.Parse(srv.URL + "/")
+		c := client.NewClientWithUrl(u, client.WithMiddleware(signer.Middleware()))
+		req, err := c.CreateRequest(http.MethodPost, "v1/organisation/accounts?foo=bar", map[string]string{"id": "fakeID"})
+		if err != nil {
+			t.Fatalf("unexpected error creating request, error %v", err)
+		}
+
+		if _, err := c.Do(context.Background(), req, nil); err != nil {
+			t.Errorf("unexpected error on signed request with %T, error %v", key, err)
+		}
+
+		if !strings.Contains(string(body), "fakeID") {
+			t.Errorf("request body not forwarded, got %s", body)
+		}
+
+		srv.Close()
+	}
+}
+
+func TestSigner_SignatureHeaderDeclaresKeyAlgorithmAndHeaders(t *testing.T) {
+	_, key, _ := ed25519.GenerateKey(rand.Reader)
+	signer, err := NewSigner("fakeKeyID", key)
+	if err != nil {
+		t.Fatalf("unexpected error creating signer, error %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "http://fake/v1/organisation/accounts", nil)
+	if err := signer.Sign(req); err != nil {
+		t.Fatalf("unexpected error signing request, error %v", err)
+	}
+
+	params := parseParams(req.Header.Get("Signature"))
+	if got, want := params["keyId"], "fakeKeyID"; got != want {
+		t.Errorf("key id does not match, expected %s got %s", want, got)
+	}
+
+	if got, want := params["algorithm"], AlgorithmEd25519; got != want {
+		t.Errorf("algorithm does not match, expected %s got %s", want, got)
+	}
+
+	if got, want := params["headers"], "(request-target) host date digest"; got != want {
+		t.Errorf("headers do not match, expected %s got %s", want, got)
+	}
+
+	if got, want := req.Header.Get("Digest"), "SHA-256=47DEQpj8HBSa+/TImW+5JCeuQeRkm5NMpJWZG3hSuFU="; got != want {
+		t.Errorf("digest does not match, expected %s got %s", want, got)
+	}
+}
+
+func TestVerifier_RejectsTamperedRequests(t *testing.T) {
+	pub, key, _ := ed25519.GenerateKey(rand.Reader)
+	otherPub, _, _ := ed25519.GenerateKey(rand.Reader)
+	signer, err := NewSigner("fakeKeyID", key)
+	if err != nil {
+		t.Fatalf("unexpected error creating signer, error %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		tamper func(req *http.Request)
+		keys   map[string]crypto.PublicKey
+		err    error
+	}{
+		{
+			name:   "body",
+			tamper: func(req *http.Request) { req.Body = ioutil.NopCloser(strings.NewReader(`{"id": "other"}`)) },
+			err:    ErrDigestMismatch,
+		},
+		{
+			name:   "path",
+			tamper: func(req *http.Request) { req.URL.Path = "/v1/organisation/other" },
+			err:    ErrInvalidSignature,
+		},
+		{
+			name:   "signature",
+			tamper: func(req *http.Request) { req.Header.Del("Signature") },
+			err:    ErrMissingSignature,
+		},
+		{
+			name: "date",
+			tamper: func(req *http.Request) {
+				req.Header.Set("Date", time.Now().Add(-time.Hour).UTC().Format(http.TimeFormat))
+			},
+			err: ErrClockSkew,
+		},
+		{
+			name: "key",
+			keys: map[string]crypto.PublicKey{"fakeKeyID": otherPub},
+			err:  ErrInvalidSignature,
+		},
+		{
+			name: "unknown key",
+			keys: map[string]crypto.PublicKey{},
+			err:  ErrUnknownKey,
+		},
+	}
+
+	for _, test := range tests {
+		req := httptest.NewRequest(http.MethodPost, "http://fake/v1/organisation/accounts", bytes.NewBufferString(`{"id": "fakeID"}`))
+		if err := signer.Sign(req); err != nil {
+			t.Fatalf("unexpected error signing request, error %v", err)
+		}
+
+		if test.tamper != nil {
+			test.tamper(req)
+		}
+
+		keys := test.keys
+		if keys == nil {
+			keys = map[string]crypto.PublicKey{"fakeKeyID": pub}
+		}
+
+		err := NewVerifier(StaticKeys(keys)).Verify(req)
+		if !errors.Is(err, test.err) {
+			t.Errorf("unexpected error on tampered %s, expected %v got %v", test.name, test.err, err)
+		}
+	}
+}
+
+func TestVerifier_RequiresDefaultHeadersToBeSigned(t *testing.T) {
+	pub, key, _ := ed25519.GenerateKey(rand.Reader)
+	signer, err := NewSigner("fakeKeyID", key, "date")
+	if err != nil {
+		t.Fatalf("unexpected error creating signer, error %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "http://fake/v1/organisation/accounts", nil)
+	if err := signer.Sign(req); err != nil {
+		t.Fatalf("unexpected error signing request, error %v", err)
+	}
+
+	err = NewVerifier(StaticKeys(map[string]crypto.PublicKey{"fakeKeyID": pub})).Verify(req)
+	if !errors.Is(err, ErrMissingHeader) {
+		t.Errorf("unexpected error type, expected missing header got %v", err)
+	}
+}
+
+func encodeKeys(t *testing.T, key crypto.Signer) ([]byte, []byte) {
+	priv, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatalf("unexpected error marshalling private key, error %v", err)
+	}
+
+	pub, err := x509.MarshalPKIXPublicKey(key.Public())
+	if err != nil {
+		t.Fatalf("unexpected error marshalling public key, error %v", err)
+	}
+
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: priv}),
+		pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pub})
+}
@@ -0,0 +1,151 @@
+package signature
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	client "github.com/marcosQuesada/finn/http"
+	"github.com/marcosQuesada/finn/internal/reqbody"
+)
+
+// AlgorithmRSASHA256 signs with RSASSA-PKCS1-v1_5 over SHA-256
+const AlgorithmRSASHA256 = "rsa-sha256"
+
+// AlgorithmEd25519 signs with Ed25519
+const AlgorithmEd25519 = "ed25519"
+
+// RequestTarget is the pseudo header holding lower cased method and request uri
+const RequestTarget = "(request-target)"
+
+// DefaultHeaders are signed when no headers are provided
+var DefaultHeaders = []string{RequestTarget, "host", "date", "digest"}
+
+// Signer signs requests following draft-cavage HTTP Signatures
+type Signer struct {
+	keyID     string
+	key       crypto.Signer
+	algorithm string
+	headers   []string
+	now       func() time.Time
+}
+
+// NewSigner instantiates a signer, algorithm is taken from key type, DefaultHeaders are signed
+// when no headers are provided
+func NewSigner(keyID string, key crypto.Signer, headers ...string) (*Signer, error) {
+	algorithm, err := algorithmOf(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(headers) == 0 {
+		headers = DefaultHeaders
+	}
+
+	return &Signer{
+		keyID:     keyID,
+		key:       key,
+		algorithm: algorithm,
+		headers:   lower(headers),
+		now:       time.Now,
+	}, nil
+}
+
+// Middleware signs each request attempt
+func (s *Signer) Middleware() client.Middleware {
+	return func(next client.RoundTripFunc) client.RoundTripFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			if err := s.Sign(req); err != nil {
+				return nil, err
+			}
+
+			return next(req)
+		}
+	}
+}
+
+// Sign sets Date and Digest headers when missing and adds Signature header
+func (s *Signer) Sign(req *http.Request) error {
+	if req.Header.Get("Date") == "" {
+		req.Header.Set("Date", s.now().UTC().Format(http.TimeFormat))
+	}
+
+	body, err := reqbody.Read(req)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Digest", digest(body))
+
+	signing, err := signingString(req, s.headers)
+	if err != nil {
+		return err
+	}
+
+	sig, err := s.sign([]byte(signing))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Signature", fmt.Sprintf(`keyId="%s",algorithm="%s",headers="%s",signature="%s"`,
+		s.keyID, s.algorithm, strings.Join(s.headers, " "), base64.StdEncoding.EncodeToString(sig)))
+
+	return nil
+}
+
+// sign signs message with key algorithm
+func (s *Signer) sign(msg []byte) ([]byte, error) {
+	if s.algorithm == AlgorithmEd25519 {
+		return s.key.Sign(rand.Reader, msg, crypto.Hash(0))
+	}
+
+	h := sha256.Sum256(msg)
+
+	return s.key.Sign(rand.Reader, h[:], crypto.SHA256)
+}
+
+// signingString builds signature base from headers, in declared order
+func signingString(req *http.Request, headers []string) (string, error) {
+	lines := make([]string, len(headers))
+	for i, h := range headers {
+		switch h {
+		case RequestTarget:
+			lines[i] = fmt.Sprintf("%s: %s %s", h, strings.ToLower(req.Method), req.URL.RequestURI())
+		case "host":
+			host := req.Host
+			if host == "" {
+				host = req.URL.Host
+			}
+			lines[i] = fmt.Sprintf("%s: %s", h, host)
+		default:
+			values, ok := req.Header[http.CanonicalHeaderKey(h)]
+			if !ok {
+				return "", fmt.Errorf("header %s, error %w", h, ErrMissingHeader)
+			}
+			lines[i] = fmt.Sprintf("%s: %s", h, strings.Join(values, ", "))
+		}
+	}
+
+	return strings.Join(lines, "\n"), nil
+}
+
+// digest computes SHA-256 Digest header value
+func digest(body []byte) string {
+	h := sha256.Sum256(body)
+
+	return "SHA-256=" + base64.StdEncoding.EncodeToString(h[:])
+}
+
+// lower lower cases header names
+func lower(headers []string) []string {
+	l := make([]string, len(headers))
+	for i, h := range headers {
+		l[i] = strings.ToLower(h)
+	}
+
+	return l
+}
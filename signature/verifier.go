@@ -0,0 +1,208 @@
+package signature
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/marcosQuesada/finn/internal/reqbody"
+	"github.com/marcosQuesada/finn/internal/strs"
+)
+
+// ErrMissingSignature happens on requests without Signature header
+var ErrMissingSignature = errors.New("missing signature")
+
+// ErrMissingHeader happens when a signed or required header is not present
+var ErrMissingHeader = errors.New("missing header")
+
+// ErrInvalidSignature happens on malformed or not matching signatures
+var ErrInvalidSignature = errors.New("invalid signature")
+
+// ErrDigestMismatch happens when Digest header does not match request body
+var ErrDigestMismatch = errors.New("digest mismatch")
+
+// ErrClockSkew happens when Date header is out of allowed skew
+var ErrClockSkew = errors.New("date out of allowed clock skew")
+
+// ErrUnknownKey happens when key id cannot be resolved
+var ErrUnknownKey = errors.New("unknown key")
+
+// KeyResolver returns public key by key id
+type KeyResolver func(keyID string) (crypto.PublicKey, error)
+
+// StaticKeys resolves keys from a fixed set
+func StaticKeys(keys map[string]crypto.PublicKey) KeyResolver {
+	return func(keyID string) (crypto.PublicKey, error) {
+		k, ok := keys[keyID]
+		if !ok {
+			return nil, fmt.Errorf("key id %s, error %w", keyID, ErrUnknownKey)
+		}
+
+		return k, nil
+	}
+}
+
+// Verifier authenticates draft-cavage HTTP Signatures on incoming requests
+type Verifier struct {
+	keys     KeyResolver
+	required []string
+	maxSkew  time.Duration
+	now      func() time.Time
+}
+
+// NewVerifier instantiates a verifier requiring DefaultHeaders to be signed and a 5 minutes Date skew
+func NewVerifier(keys KeyResolver) *Verifier {
+	return &Verifier{
+		keys:     keys,
+		required: DefaultHeaders,
+		maxSkew:  5 * time.Minute,
+		now:      time.Now,
+	}
+}
+
+// Handler rejects requests without a valid signature with 401 status code
+func (v *Verifier) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := v.Verify(r); err != nil {
+			w.Header().Set("Content-Type", "application/vnd.api+json")
+			w.WriteHeader(http.StatusUnauthorized)
+			_ = json.NewEncoder(w).Encode(map[string]string{"error_message": err.Error()})
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Verify checks required headers are signed, Date skew, Digest and signature
+func (v *Verifier) Verify(req *http.Request) error {
+	raw := req.Header.Get("Signature")
+	if raw == "" {
+		return ErrMissingSignature
+	}
+
+	params := parseParams(raw)
+	headers := strings.Fields(params["headers"])
+	if len(headers) == 0 {
+		headers = []string{"date"}
+	}
+
+	for _, h := range v.required {
+		if !strs.Contains(headers, h) {
+			return fmt.Errorf("%s not signed, error %w", h, ErrMissingHeader)
+		}
+	}
+
+	if err := v.verifyDate(req); err != nil {
+		return err
+	}
+
+	if err := verifyDigest(req); err != nil {
+		return err
+	}
+
+	key, err := v.keys(params["keyId"])
+	if err != nil {
+		return err
+	}
+
+	sig, err := base64.StdEncoding.DecodeString(params["signature"])
+	if err != nil {
+		return fmt.Errorf("undecodable signature, error %w", ErrInvalidSignature)
+	}
+
+	signing, err := signingString(req, headers)
+	if err != nil {
+		return err
+	}
+
+	return verify(key, params["algorithm"], []byte(signing), sig)
+}
+
+// verifyDate checks Date header skew
+func (v *Verifier) verifyDate(req *http.Request) error {
+	raw := req.Header.Get("Date")
+	if raw == "" {
+		return fmt.Errorf("date, error %w", ErrMissingHeader)
+	}
+
+	d, err := http.ParseTime(raw)
+	if err != nil {
+		return fmt.Errorf("unparseable date, error %w", ErrClockSkew)
+	}
+
+	skew := v.now().Sub(d)
+	if skew < -v.maxSkew || skew > v.maxSkew {
+		return ErrClockSkew
+	}
+
+	return nil
+}
+
+// verifyDigest checks Digest header against request body
+func verifyDigest(req *http.Request) error {
+	raw := req.Header.Get("Digest")
+	if raw == "" {
+		return fmt.Errorf("digest, error %w", ErrMissingHeader)
+	}
+
+	body, err := reqbody.Read(req)
+	if err != nil {
+		return err
+	}
+
+	if raw != digest(body) {
+		return ErrDigestMismatch
+	}
+
+	return nil
+}
+
+// verify checks signature with public key, algorithm has to match key type
+func verify(key crypto.PublicKey, algorithm string, msg, sig []byte) error {
+	expected, err := algorithmOf(key)
+	if err != nil {
+		return err
+	}
+
+	if algorithm != "" && algorithm != expected && algorithm != "hs2019" {
+		return fmt.Errorf("algorithm %s, error %w", algorithm, ErrInvalidSignature)
+	}
+
+	switch k := key.(type) {
+	case *rsa.PublicKey:
+		h := sha256.Sum256(msg)
+		if rsa.VerifyPKCS1v15(k, crypto.SHA256, h[:], sig) != nil {
+			return ErrInvalidSignature
+		}
+	case ed25519.PublicKey:
+		if !ed25519.Verify(k, msg, sig) {
+			return ErrInvalidSignature
+		}
+	}
+
+	return nil
+}
+
+// parseParams parses signature header comma separated key="value" pairs
+func parseParams(raw string) map[string]string {
+	params := make(map[string]string)
+	for _, part := range strings.Split(raw, ",") {
+		i := strings.Index(part, "=")
+		if i < 0 {
+			continue
+		}
+
+		params[strings.TrimSpace(part[:i])] = strings.Trim(strings.TrimSpace(part[i+1:]), `"`)
+	}
+
+	return params
+}
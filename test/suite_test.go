@@ -6,16 +6,46 @@ import (
 	"context"
 	"errors"
 	"log"
+	"net/url"
+	"os"
 	"testing"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/marcosQuesada/finn"
+	"github.com/marcosQuesada/finn/finntest"
 	"github.com/marcosQuesada/finn/http"
 )
 
 var userID = uuid.New().String()
 
+// baseURL points to ACCOUNT_API_URL live server, or to an in-memory fake server when not set
+var baseURL *url.URL
+
+// live flags suite is running against a live account api server
+var live bool
+
+func TestMain(m *testing.M) {
+	if addr := os.Getenv("ACCOUNT_API_URL"); addr != "" {
+		u, err := url.Parse(addr)
+		if err != nil {
+			log.Fatalf("unexpected error parsing account api url, error %v", err)
+		}
+		baseURL, live = u, true
+		os.Exit(m.Run())
+	}
+
+	srv := finntest.NewServer()
+	baseURL = srv.BaseURL()
+	code := m.Run()
+	srv.Close()
+	os.Exit(code)
+}
+
+func newClient() *http.Client {
+	return http.NewClientWithUrl(baseURL)
+}
+
 func TestAccountSuite(t *testing.T) {
 	t.Run("CreateAccountWithValidParametersDoesNotThrowError", testCreateAccountWithValidParametersDoesNotThrowError)
 	t.Run("FetchAccountOnAlreadyCreatedUserDoesNotThrowError", testFetchAccountOnAlreadyCreatedUserDoesNotThrowError)
@@ -27,7 +57,7 @@ func TestAccountSuite(t *testing.T) {
 }
 
 func testCreateAccountWithValidParametersDoesNotThrowError(t *testing.T) {
-	cl := http.NewClient()
+	cl := newClient()
 	a := finn.NewAPIClient(cl)
 
 	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
@@ -59,7 +89,7 @@ func testCreateAccountWithValidParametersDoesNotThrowError(t *testing.T) {
 }
 
 func testFetchAccountOnAlreadyCreatedUserDoesNotThrowError(t *testing.T) {
-	cl := http.NewClient()
+	cl := newClient()
 	a := finn.NewAPIClient(cl)
 
 	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
@@ -80,7 +110,7 @@ func testFetchAccountOnAlreadyCreatedUserDoesNotThrowError(t *testing.T) {
 }
 
 func testFetchAccountOnNonCreatedUserThrowNonExistentError(t *testing.T) {
-	cl := http.NewClient()
+	cl := newClient()
 	a := finn.NewAPIClient(cl)
 
 	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
@@ -98,7 +128,7 @@ func testFetchAccountOnNonCreatedUserThrowNonExistentError(t *testing.T) {
 }
 
 func testListAccountsWithPaginationDoesNotThrowErrorAndReturnsASubSetOfResults(t *testing.T) {
-	cl := http.NewClient()
+	cl := newClient()
 	a := finn.NewAPIClient(cl)
 
 	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
@@ -141,7 +171,7 @@ func testListAccountsWithPaginationDoesNotThrowErrorAndReturnsASubSetOfResults(t
 }
 
 func testDeleteAccountOnExistentAccountDoesNotThrowError(t *testing.T) {
-	cl := http.NewClient()
+	cl := newClient()
 	a := finn.NewAPIClient(cl)
 
 	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
@@ -155,8 +185,10 @@ func testDeleteAccountOnExistentAccountDoesNotThrowError(t *testing.T) {
 }
 
 func testDeleteAccountOnNonExistentAccountThrowsNotFoundError(t *testing.T) {
-	t.Skip("api server returning 204 status codes on expected not existent accounts")
-	cl := http.NewClient()
+	if live {
+		t.Skip("api server returning 204 status codes on expected not existent accounts")
+	}
+	cl := newClient()
 	a := finn.NewAPIClient(cl)
 
 	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
@@ -174,8 +206,10 @@ func testDeleteAccountOnNonExistentAccountThrowsNotFoundError(t *testing.T) {
 }
 
 func testDeleteAccountOnInvalidVersionThrowConflictError(t *testing.T) {
-	t.Skip("api server returning 404 status codes on expected conflict error")
-	cl := http.NewClient()
+	if live {
+		t.Skip("api server returning 404 status codes on expected conflict error")
+	}
+	cl := newClient()
 	a := finn.NewAPIClient(cl)
 
 	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
@@ -219,13 +253,12 @@ func setup(ctx context.Context, cl *finn.APIClient, size int) ([]string, error)
 }
 
 func tearDown(ctx context.Context, cl *finn.APIClient, userIDs []string) error {
-	version := 0
+	versions := make(map[string]int, len(userIDs))
 	for _, u := range userIDs {
-		err := cl.Delete(ctx, u, version)
-		if err != nil {
-			return err
-		}
+		versions[u] = 0
 	}
 
-	return nil
+	_, err := cl.DeleteMany(ctx, versions)
+
+	return err
 }
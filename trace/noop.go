@@ -0,0 +1,31 @@
+package trace
+
+import "context"
+
+// Noop is a Tracer that records nothing, spans keep parent span context so that
+// trace context is still propagated
+type Noop struct{}
+
+// Start returns ctx untouched and a no-op span
+func (Noop) Start(ctx context.Context, name string) (context.Context, Span) {
+	return ctx, noopSpan{sc: SpanContextFromContext(ctx)}
+}
+
+// noopSpan discards everything
+type noopSpan struct {
+	sc SpanContext
+}
+
+// SpanContext returns span context
+func (s noopSpan) SpanContext() SpanContext {
+	return s.sc
+}
+
+// SetAttribute discards attribute
+func (noopSpan) SetAttribute(key string, value interface{}) {}
+
+// RecordError discards error
+func (noopSpan) RecordError(err error) {}
+
+// End does nothing
+func (noopSpan) End() {}
@@ -0,0 +1,107 @@
+package trace
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// TraceparentHeader carries W3C trace context version, trace id, parent span id and flags
+const TraceparentHeader = "traceparent"
+
+// TracestateHeader carries W3C vendor specific trace state
+const TracestateHeader = "tracestate"
+
+const sampledFlag = 0x01
+
+// ErrInvalidTraceparent happens on malformed traceparent headers
+var ErrInvalidTraceparent = errors.New("invalid traceparent")
+
+// Inject sets traceparent and tracestate headers from ctx span context, nothing is set when
+// ctx holds no valid span context
+func Inject(ctx context.Context, h http.Header) {
+	sc := SpanContextFromContext(ctx)
+	if !sc.IsValid() {
+		return
+	}
+
+	h.Set(TraceparentHeader, Traceparent(sc))
+	if sc.TraceState != "" {
+		h.Set(TracestateHeader, sc.TraceState)
+	} else {
+		h.Del(TracestateHeader)
+	}
+}
+
+// Extract parses traceparent and tracestate headers
+func Extract(h http.Header) (SpanContext, error) {
+	sc, err := ParseTraceparent(h.Get(TraceparentHeader))
+	if err != nil {
+		return SpanContext{}, err
+	}
+
+	sc.TraceState = strings.Join(h[http.CanonicalHeaderKey(TracestateHeader)], ",")
+
+	return sc, nil
+}
+
+// Traceparent renders span context as a version 00 traceparent value
+func Traceparent(sc SpanContext) string {
+	var flags byte
+	if sc.Sampled {
+		flags |= sampledFlag
+	}
+
+	return fmt.Sprintf("00-%s-%s-%02x", sc.TraceID, sc.SpanID, flags)
+}
+
+// ParseTraceparent parses a traceparent value, fields beyond version 00 ones are ignored
+// as the specification asks for future versions
+func ParseTraceparent(v string) (SpanContext, error) {
+	parts := strings.Split(strings.TrimSpace(v), "-")
+	if len(parts) < 4 {
+		return SpanContext{}, fmt.Errorf("%q, error %w", v, ErrInvalidTraceparent)
+	}
+
+	version, err := decodeHex(parts[0], 1)
+	if err != nil || version[0] == 0xff || (version[0] == 0 && len(parts) != 4) {
+		return SpanContext{}, fmt.Errorf("version %q, error %w", parts[0], ErrInvalidTraceparent)
+	}
+
+	sc := SpanContext{}
+	traceID, err := decodeHex(parts[1], len(sc.TraceID))
+	if err != nil {
+		return SpanContext{}, fmt.Errorf("trace id %q, error %w", parts[1], ErrInvalidTraceparent)
+	}
+	copy(sc.TraceID[:], traceID)
+
+	spanID, err := decodeHex(parts[2], len(sc.SpanID))
+	if err != nil {
+		return SpanContext{}, fmt.Errorf("span id %q, error %w", parts[2], ErrInvalidTraceparent)
+	}
+	copy(sc.SpanID[:], spanID)
+
+	flags, err := decodeHex(parts[3], 1)
+	if err != nil {
+		return SpanContext{}, fmt.Errorf("flags %q, error %w", parts[3], ErrInvalidTraceparent)
+	}
+	sc.Sampled = flags[0]&sampledFlag != 0
+
+	if !sc.IsValid() {
+		return SpanContext{}, fmt.Errorf("all zero ids, error %w", ErrInvalidTraceparent)
+	}
+
+	return sc, nil
+}
+
+// decodeHex decodes a lowercase hex field of n bytes
+func decodeHex(v string, n int) ([]byte, error) {
+	if len(v) != 2*n || strings.ToLower(v) != v {
+		return nil, ErrInvalidTraceparent
+	}
+
+	return hex.DecodeString(v)
+}
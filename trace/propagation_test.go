@@ -0,0 +1,92 @@
+package trace
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func TestTraceparentRoundTrip(t *testing.T) {
+	raw := "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+
+	sc, err := ParseTraceparent(raw)
+	if err != nil {
+		t.Fatalf("unexpected error parsing traceparent, error %v", err)
+	}
+
+	if got, want := sc.TraceID.String(), "4bf92f3577b34da6a3ce929d0e0e4736"; got != want {
+		t.Errorf("unexpected trace id, expected %s got %s", want, got)
+	}
+
+	if got, want := sc.SpanID.String(), "00f067aa0ba902b7"; got != want {
+		t.Errorf("unexpected span id, expected %s got %s", want, got)
+	}
+
+	if !sc.Sampled {
+		t.Error("expected sampled flag")
+	}
+
+	if got := Traceparent(sc); got != raw {
+		t.Errorf("unexpected traceparent, expected %s got %s", raw, got)
+	}
+}
+
+func TestParseTraceparentRejectsMalformedValues(t *testing.T) {
+	values := []string{
+		"",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7",
+		"00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01",
+		"00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra",
+		"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e47-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-zz",
+	}
+
+	for _, v := range values {
+		if _, err := ParseTraceparent(v); !errors.Is(err, ErrInvalidTraceparent) {
+			t.Errorf("expected invalid traceparent on %q, got %v", v, err)
+		}
+	}
+}
+
+func TestParseTraceparentAcceptsFutureVersionFields(t *testing.T) {
+	_, err := ParseTraceparent("01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra")
+	if err != nil {
+		t.Errorf("unexpected error parsing future version, error %v", err)
+	}
+}
+
+func TestInjectAndExtractCarryTraceState(t *testing.T) {
+	h := make(http.Header)
+	h.Set(TraceparentHeader, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00")
+	h.Add(TracestateHeader, "congo=t61rcWkgMzE")
+	h.Add(TracestateHeader, "rojo=00f067aa0ba902b7")
+
+	sc, err := Extract(h)
+	if err != nil {
+		t.Fatalf("unexpected error extracting span context, error %v", err)
+	}
+
+	out := make(http.Header)
+	Inject(ContextWithRemoteSpanContext(context.Background(), sc), out)
+
+	if got, want := out.Get(TraceparentHeader), h.Get(TraceparentHeader); got != want {
+		t.Errorf("unexpected traceparent, expected %s got %s", want, got)
+	}
+
+	if got, want := out.Get(TracestateHeader), "congo=t61rcWkgMzE,rojo=00f067aa0ba902b7"; got != want {
+		t.Errorf("unexpected tracestate, expected %s got %s", want, got)
+	}
+}
+
+func TestInjectSetsNothingWithoutSpanContext(t *testing.T) {
+	h := make(http.Header)
+	Inject(context.Background(), h)
+
+	if len(h) != 0 {
+		t.Errorf("unexpected headers, got %v", h)
+	}
+}
@@ -0,0 +1,145 @@
+package trace
+
+import (
+	"context"
+	"crypto/rand"
+	"sync"
+	"time"
+)
+
+// RecordedSpan is an ended span snapshot
+type RecordedSpan struct {
+	Name        string
+	SpanContext SpanContext
+	Parent      SpanContext
+	Attributes  map[string]interface{}
+	Errors      []error
+	Start       time.Time
+	End         time.Time
+}
+
+// Recorder is an in-memory Tracer, ended spans can be read back on tests
+type Recorder struct {
+	mu    sync.Mutex
+	ended []RecordedSpan
+}
+
+// NewRecorder instantiates an in-memory tracer
+func NewRecorder() *Recorder {
+	return &Recorder{}
+}
+
+// Start starts a span, child of ctx span when there is one, and binds it to returned context
+func (r *Recorder) Start(ctx context.Context, name string) (context.Context, Span) {
+	parent := SpanContextFromContext(ctx)
+	sc := SpanContext{
+		TraceID:    parent.TraceID,
+		Sampled:    true,
+		TraceState: parent.TraceState,
+	}
+	if !sc.TraceID.IsValid() {
+		_, _ = rand.Read(sc.TraceID[:])
+	}
+	_, _ = rand.Read(sc.SpanID[:])
+
+	s := &recordedSpan{
+		recorder: r,
+		data: RecordedSpan{
+			Name:        name,
+			SpanContext: sc,
+			Parent:      parent,
+			Attributes:  make(map[string]interface{}),
+			Start:       time.Now(),
+		},
+	}
+
+	return ContextWithSpan(ctx, s), s
+}
+
+// Spans returns ended spans in ending order
+func (r *Recorder) Spans() []RecordedSpan {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	spans := make([]RecordedSpan, len(r.ended))
+	copy(spans, r.ended)
+
+	return spans
+}
+
+// Named returns ended spans by name
+func (r *Recorder) Named(name string) []RecordedSpan {
+	var spans []RecordedSpan
+	for _, s := range r.Spans() {
+		if s.Name == name {
+			spans = append(spans, s)
+		}
+	}
+
+	return spans
+}
+
+// Reset drops ended spans
+func (r *Recorder) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.ended = nil
+}
+
+// recordedSpan is a Recorder span, changes after End are ignored
+type recordedSpan struct {
+	recorder *Recorder
+	data     RecordedSpan
+	ended    bool
+}
+
+// SpanContext returns span context
+func (s *recordedSpan) SpanContext() SpanContext {
+	return s.data.SpanContext
+}
+
+// SetAttribute sets span attribute, replacing previous value
+func (s *recordedSpan) SetAttribute(key string, value interface{}) {
+	s.recorder.mu.Lock()
+	defer s.recorder.mu.Unlock()
+
+	if !s.ended {
+		s.data.Attributes[key] = value
+	}
+}
+
+// RecordError records a span failure, nil errors are ignored
+func (s *recordedSpan) RecordError(err error) {
+	if err == nil {
+		return
+	}
+
+	s.recorder.mu.Lock()
+	defer s.recorder.mu.Unlock()
+
+	if !s.ended {
+		s.data.Errors = append(s.data.Errors, err)
+	}
+}
+
+// End ends span and stores its snapshot, only first call counts
+func (s *recordedSpan) End() {
+	s.recorder.mu.Lock()
+	defer s.recorder.mu.Unlock()
+
+	if s.ended {
+		return
+	}
+	s.ended = true
+	s.data.End = time.Now()
+
+	attrs := make(map[string]interface{}, len(s.data.Attributes))
+	for k, v := range s.data.Attributes {
+		attrs[k] = v
+	}
+
+	snapshot := s.data
+	snapshot.Attributes = attrs
+	s.recorder.ended = append(s.recorder.ended, snapshot)
+}
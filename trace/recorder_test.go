@@ -0,0 +1,97 @@
+package trace
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestRecorderRecordsChildSpansOnParentTrace(t *testing.T) {
+	r := NewRecorder()
+
+	ctx, parent := r.Start(context.Background(), "parent")
+	_, child := r.Start(ctx, "child")
+	child.SetAttribute("http.status_code", 503)
+	child.RecordError(errors.New("unavailable"))
+	child.RecordError(nil)
+	child.End()
+	parent.End()
+
+	spans := r.Spans()
+	if len(spans) != 2 {
+		t.Fatalf("unexpected spans size, got %d", len(spans))
+	}
+
+	c, p := spans[0], spans[1]
+	if c.Name != "child" || p.Name != "parent" {
+		t.Fatalf("unexpected spans order, got %s %s", c.Name, p.Name)
+	}
+
+	if c.SpanContext.TraceID != p.SpanContext.TraceID {
+		t.Error("child span does not share parent trace id")
+	}
+
+	if c.Parent.SpanID != p.SpanContext.SpanID {
+		t.Error("child span parent does not match")
+	}
+
+	if p.Parent.IsValid() {
+		t.Error("unexpected root span parent")
+	}
+
+	if got := c.Attributes["http.status_code"]; got != 503 {
+		t.Errorf("unexpected status code attribute, got %v", got)
+	}
+
+	if len(c.Errors) != 1 {
+		t.Errorf("unexpected recorded errors, got %v", c.Errors)
+	}
+}
+
+func TestRecorderContinuesRemoteTrace(t *testing.T) {
+	remote, err := ParseTraceparent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	if err != nil {
+		t.Fatalf("unexpected error parsing traceparent, error %v", err)
+	}
+	remote.TraceState = "congo=t61rcWkgMzE"
+
+	r := NewRecorder()
+	_, span := r.Start(ContextWithRemoteSpanContext(context.Background(), remote), "op")
+	span.End()
+
+	s := r.Named("op")[0]
+	if s.SpanContext.TraceID != remote.TraceID || s.Parent.SpanID != remote.SpanID {
+		t.Error("span does not continue remote trace")
+	}
+
+	if s.SpanContext.TraceState != remote.TraceState {
+		t.Errorf("unexpected trace state, got %s", s.SpanContext.TraceState)
+	}
+}
+
+func TestRecorderIgnoresChangesAfterEnd(t *testing.T) {
+	r := NewRecorder()
+	_, span := r.Start(context.Background(), "op")
+	span.End()
+	span.SetAttribute("late", true)
+	span.End()
+
+	spans := r.Spans()
+	if len(spans) != 1 {
+		t.Fatalf("unexpected spans size, got %d", len(spans))
+	}
+
+	if _, ok := spans[0].Attributes["late"]; ok {
+		t.Error("unexpected attribute set after end")
+	}
+}
+
+func TestNoopKeepsParentSpanContext(t *testing.T) {
+	remote, _ := ParseTraceparent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	ctx := ContextWithRemoteSpanContext(context.Background(), remote)
+
+	_, span := Noop{}.Start(ctx, "op")
+	if span.SpanContext() != remote {
+		t.Error("noop span does not keep parent span context")
+	}
+}
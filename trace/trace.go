@@ -0,0 +1,88 @@
+// Package trace defines dependency free tracing hooks, a no-op Tracer, an in-memory Recorder
+// and W3C trace context (traceparent and tracestate headers) propagation
+package trace
+
+import (
+	"context"
+	"encoding/hex"
+)
+
+// TraceID identifies a whole trace
+type TraceID [16]byte
+
+// String returns lowercase hex encoded trace id
+func (t TraceID) String() string {
+	return hex.EncodeToString(t[:])
+}
+
+// IsValid checks trace id is not all zeros
+func (t TraceID) IsValid() bool {
+	return t != TraceID{}
+}
+
+// SpanID identifies a span inside a trace
+type SpanID [8]byte
+
+// String returns lowercase hex encoded span id
+func (s SpanID) String() string {
+	return hex.EncodeToString(s[:])
+}
+
+// IsValid checks span id is not all zeros
+func (s SpanID) IsValid() bool {
+	return s != SpanID{}
+}
+
+// SpanContext holds span identity propagated across process boundaries
+type SpanContext struct {
+	TraceID    TraceID
+	SpanID     SpanID
+	Sampled    bool
+	TraceState string
+}
+
+// IsValid checks both trace and span ids are set
+func (sc SpanContext) IsValid() bool {
+	return sc.TraceID.IsValid() && sc.SpanID.IsValid()
+}
+
+// Span is a timed operation, attributes describe it and errors record its failures
+type Span interface {
+	SpanContext() SpanContext
+	SetAttribute(key string, value interface{})
+	RecordError(err error)
+	End()
+}
+
+// Tracer starts spans, child of the span found on ctx when there is one
+type Tracer interface {
+	Start(ctx context.Context, name string) (context.Context, Span)
+}
+
+// spanKey holds current span on context
+type spanKey struct{}
+
+// ContextWithSpan binds span to ctx, spans started from it become its children
+func ContextWithSpan(ctx context.Context, span Span) context.Context {
+	return context.WithValue(ctx, spanKey{}, span)
+}
+
+// ContextWithRemoteSpanContext binds a span context received from a remote caller, as extracted
+// from incoming request headers, so that outgoing spans and requests continue its trace
+func ContextWithRemoteSpanContext(ctx context.Context, sc SpanContext) context.Context {
+	return ContextWithSpan(ctx, noopSpan{sc: sc})
+}
+
+// SpanFromContext returns current span, a no-op span is returned when ctx holds none
+func SpanFromContext(ctx context.Context) Span {
+	if s, ok := ctx.Value(spanKey{}).(Span); ok {
+		return s
+	}
+
+	return noopSpan{}
+}
+
+// SpanContextFromContext returns current span context, zero value when ctx holds no span
+func SpanContextFromContext(ctx context.Context) SpanContext {
+	return SpanFromContext(ctx).SpanContext()
+}
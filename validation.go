@@ -0,0 +1,312 @@
+package finn
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/marcosQuesada/finn/iban"
+)
+
+var countryPattern = regexp.MustCompile(`^[A-Z]{2}$`)
+var currencyPattern = regexp.MustCompile(`^[A-Z]{3}$`)
+
+// FieldError describes a single field violation, field is declared as json path
+type FieldError struct {
+	Field   string
+	Message string
+}
+
+// Error describes field violation
+func (e *FieldError) Error() string {
+	return fmt.Sprintf("%s %s", e.Field, e.Message)
+}
+
+// ValidationError lists every account field violation
+type ValidationError struct {
+	Fields []*FieldError
+}
+
+// Error joins all field violations
+func (e *ValidationError) Error() string {
+	msgs := make([]string, len(e.Fields))
+	for i, f := range e.Fields {
+		msgs[i] = f.Error()
+	}
+
+	return fmt.Sprintf("invalid account: %s", strings.Join(msgs, "; "))
+}
+
+// Unwrap enables errors.Is matching against ErrInvalidAccount
+func (e *ValidationError) Unwrap() error {
+	return ErrInvalidAccount
+}
+
+// add appends a field violation
+func (e *ValidationError) add(field, format string, args ...interface{}) {
+	e.Fields = append(e.Fields, &FieldError{
+		Field:   field,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+// format defines a field pattern with its human description
+type format struct {
+	pattern     *regexp.Regexp
+	description string
+}
+
+// countryRule defines per country account attribute rules, nil bank id forbids it
+// as empty bank id code does
+type countryRule struct {
+	bankID             *format
+	bankIDRequired     bool
+	bankIDCode         string
+	bankIDCodeRequired bool
+	bicRequired        bool
+	accountNumber      *format
+	ibanForbidden      bool
+}
+
+// newFormat compiles a field format
+func newFormat(pattern, description string) *format {
+	return &format{
+		pattern:     regexp.MustCompile(pattern),
+		description: description,
+	}
+}
+
+// countryRules declares account api rules by country
+var countryRules = map[string]*countryRule{
+	"GB": {
+		bankID:             newFormat(`^\d{6}$`, "6 digits"),
+		bankIDRequired:     true,
+		bankIDCode:         "GBDSC",
+		bankIDCodeRequired: true,
+		bicRequired:        true,
+		accountNumber:      newFormat(`^\d{8}$`, "8 digits"),
+	},
+	"AU": {
+		bankID:             newFormat(`^\d{6}$`, "6 digits"),
+		bankIDCode:         "AUBSB",
+		bankIDCodeRequired: true,
+		bicRequired:        true,
+		accountNumber:      newFormat(`^[1-9]\d{5,9}$`, "6 to 10 digits, first one not 0"),
+		ibanForbidden:      true,
+	},
+	"BE": {
+		bankID:             newFormat(`^\d{3}$`, "3 digits"),
+		bankIDRequired:     true,
+		bankIDCode:         "BE",
+		bankIDCodeRequired: true,
+		accountNumber:      newFormat(`^\d{7}$`, "7 digits"),
+	},
+	"CA": {
+		bankID:             newFormat(`^0\d{8}$`, "9 digits starting with 0"),
+		bankIDCode:         "CACPA",
+		bankIDCodeRequired: true,
+		bicRequired:        true,
+		accountNumber:      newFormat(`^\d{7,12}$`, "7 to 12 digits"),
+		ibanForbidden:      true,
+	},
+	"FR": {
+		bankID:             newFormat(`^[0-9A-Z]{10}$`, "10 alphanumeric characters"),
+		bankIDRequired:     true,
+		bankIDCode:         "FR",
+		bankIDCodeRequired: true,
+		accountNumber:      newFormat(`^[0-9A-Z]{10}$`, "10 alphanumeric characters"),
+	},
+	"DE": {
+		bankID:             newFormat(`^\d{8}$`, "8 digits"),
+		bankIDRequired:     true,
+		bankIDCode:         "DEBLZ",
+		bankIDCodeRequired: true,
+		accountNumber:      newFormat(`^\d{7}$`, "7 digits"),
+	},
+	"GR": {
+		bankID:             newFormat(`^\d{7}$`, "7 digits"),
+		bankIDRequired:     true,
+		bankIDCode:         "GRBIC",
+		bankIDCodeRequired: true,
+		accountNumber:      newFormat(`^\d{16}$`, "16 digits"),
+	},
+	"HK": {
+		bankID:             newFormat(`^\d{3}$`, "3 digits"),
+		bankIDCode:         "HKNCC",
+		bankIDCodeRequired: true,
+		bicRequired:        true,
+		accountNumber:      newFormat(`^\d{9,12}$`, "9 to 12 digits"),
+		ibanForbidden:      true,
+	},
+	"IT": {
+		bankID:             newFormat(`^\d{10,11}$`, "10 or 11 digits"),
+		bankIDRequired:     true,
+		bankIDCode:         "ITNCC",
+		bankIDCodeRequired: true,
+		accountNumber:      newFormat(`^\d{12}$`, "12 digits"),
+	},
+	"LU": {
+		bankID:             newFormat(`^\d{3}$`, "3 digits"),
+		bankIDRequired:     true,
+		bankIDCode:         "LULUX",
+		bankIDCodeRequired: true,
+		accountNumber:      newFormat(`^\d{13}$`, "13 digits"),
+	},
+	"NL": {
+		bicRequired:   true,
+		accountNumber: newFormat(`^\d{10}$`, "10 digits"),
+	},
+	"PL": {
+		bankID:             newFormat(`^\d{8}$`, "8 digits"),
+		bankIDRequired:     true,
+		bankIDCode:         "PLKNR",
+		bankIDCodeRequired: true,
+		accountNumber:      newFormat(`^\d{16}$`, "16 digits"),
+	},
+	"PT": {
+		bankID:             newFormat(`^\d{8}$`, "8 digits"),
+		bankIDRequired:     true,
+		bankIDCode:         "PTNCC",
+		bankIDCodeRequired: true,
+		accountNumber:      newFormat(`^\d{11}$`, "11 digits"),
+	},
+	"ES": {
+		bankID:             newFormat(`^\d{8}$`, "8 digits"),
+		bankIDRequired:     true,
+		bankIDCode:         "ESNCC",
+		bankIDCodeRequired: true,
+		accountNumber:      newFormat(`^\d{10}$`, "10 digits"),
+	},
+	"CH": {
+		bankID:             newFormat(`^\d{5}$`, "5 digits"),
+		bankIDRequired:     true,
+		bankIDCode:         "CHBCC",
+		bankIDCodeRequired: true,
+		accountNumber:      newFormat(`^\d{12}$`, "12 digits"),
+	},
+	"US": {
+		bankID:             newFormat(`^\d{9}$`, "9 digits"),
+		bankIDRequired:     true,
+		bankIDCode:         "USABA",
+		bankIDCodeRequired: true,
+		bicRequired:        true,
+		accountNumber:      newFormat(`^\d{6,17}$`, "6 to 17 digits"),
+		ibanForbidden:      true,
+	},
+}
+
+// Validate applies account api rules, including per country attribute rules,
+// returns a *ValidationError listing every violation
+func (a *Account) Validate() error {
+	v := &ValidationError{}
+	if a == nil || a.AccoundData == nil {
+		v.add("data", "is required")
+		return v
+	}
+
+	d := a.AccoundData
+	if d.Type != "accounts" {
+		v.add("data.type", "must be accounts")
+	}
+
+	if _, err := uuid.Parse(d.ID); err != nil {
+		v.add("data.id", "must be a valid uuid")
+	}
+
+	if _, err := uuid.Parse(d.OrganisationID); err != nil {
+		v.add("data.organisation_id", "must be a valid uuid")
+	}
+
+	if d.Version < 0 {
+		v.add("data.version", "must not be negative")
+	}
+
+	if d.Attributes == nil {
+		v.add("data.attributes", "is required")
+	} else {
+		validateAttributes(d.Attributes, v)
+	}
+
+	if len(v.Fields) > 0 {
+		return v
+	}
+
+	return nil
+}
+
+// validateAttributes applies generic and per country attribute rules
+func validateAttributes(attr *Attributes, v *ValidationError) {
+	if !countryPattern.MatchString(attr.Country) {
+		v.add("data.attributes.country", "must be an ISO 3166-1 alpha-2 code")
+	}
+
+	if attr.BaseCurrency != "" && !currencyPattern.MatchString(attr.BaseCurrency) {
+		v.add("data.attributes.base_currency", "must be an ISO 4217 code")
+	}
+
+	if attr.Bic != "" && iban.ValidateBIC(attr.Bic) != nil {
+		v.add("data.attributes.bic", "must be 8 or 11 characters BIC")
+	}
+
+	rule, ok := countryRules[attr.Country]
+	if !ok {
+		validateIban(attr, v)
+		return
+	}
+
+	validateBankID(attr, rule, v)
+
+	if rule.bicRequired && attr.Bic == "" {
+		v.add("data.attributes.bic", "is required for country %s", attr.Country)
+	}
+
+	if attr.AccountNumber != "" && !rule.accountNumber.pattern.MatchString(attr.AccountNumber) {
+		v.add("data.attributes.account_number", "must be %s for country %s", rule.accountNumber.description, attr.Country)
+	}
+
+	if rule.ibanForbidden && attr.Iban != "" {
+		v.add("data.attributes.iban", "is not supported for country %s", attr.Country)
+		return
+	}
+
+	validateIban(attr, v)
+}
+
+// validateIban checks IBAN structure, checksum and country when present
+func validateIban(attr *Attributes, v *ValidationError) {
+	if attr.Iban == "" {
+		return
+	}
+
+	i, err := iban.Parse(attr.Iban)
+	if err != nil {
+		v.add("data.attributes.iban", "must be a valid IBAN, %v", err)
+		return
+	}
+
+	if i.CountryCode != attr.Country {
+		v.add("data.attributes.iban", "must belong to country %s", attr.Country)
+	}
+}
+
+// validateBankID checks bank id and bank id code presence and format
+func validateBankID(attr *Attributes, rule *countryRule, v *ValidationError) {
+	switch {
+	case rule.bankID == nil && attr.BankID != "":
+		v.add("data.attributes.bank_id", "is not supported for country %s", attr.Country)
+	case rule.bankIDRequired && attr.BankID == "":
+		v.add("data.attributes.bank_id", "is required for country %s", attr.Country)
+	case attr.BankID != "" && !rule.bankID.pattern.MatchString(attr.BankID):
+		v.add("data.attributes.bank_id", "must be %s for country %s", rule.bankID.description, attr.Country)
+	}
+
+	switch {
+	case rule.bankIDCode == "" && attr.BankIDCode != "":
+		v.add("data.attributes.bank_id_code", "is not supported for country %s", attr.Country)
+	case rule.bankIDCodeRequired && attr.BankIDCode == "":
+		v.add("data.attributes.bank_id_code", "is required for country %s", attr.Country)
+	case attr.BankIDCode != "" && attr.BankIDCode != rule.bankIDCode:
+		v.add("data.attributes.bank_id_code", "must be %s for country %s", rule.bankIDCode, attr.Country)
+	}
+}
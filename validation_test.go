@@ -0,0 +1,186 @@
+package finn
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestValidateAcceptsValidAccountsByCountry(t *testing.T) {
+	tests := []*Attributes{
+		{Country: "GB", BaseCurrency: "GBP", BankID: "400300", BankIDCode: "GBDSC", Bic: "NWBKGB22", AccountNumber: "41426819"},
+		{Country: "AU", Bic: "NWBKAU22", BankIDCode: "AUBSB"},
+		{Country: "BE", BankID: "123", BankIDCode: "BE", AccountNumber: "1234567"},
+		{Country: "CA", Bic: "NWBKCA22", BankID: "012345678", BankIDCode: "CACPA"},
+		{Country: "FR", BankID: "2004101005", BankIDCode: "FR"},
+		{Country: "DE", BankID: "37040044", BankIDCode: "DEBLZ", AccountNumber: "0532013"},
+		{Country: "GR", BankID: "0110125", BankIDCode: "GRBIC"},
+		{Country: "HK", Bic: "NWBKHK22", BankIDCode: "HKNCC"},
+		{Country: "IT", BankID: "0542811101", BankIDCode: "ITNCC", AccountNumber: "000000123456"},
+		{Country: "LU", BankID: "001", BankIDCode: "LULUX"},
+		{Country: "NL", Bic: "ABNANL2A"},
+		{Country: "PL", BankID: "10901014", BankIDCode: "PLKNR"},
+		{Country: "PT", BankID: "00020123", BankIDCode: "PTNCC"},
+		{Country: "ES", BankID: "21000418", BankIDCode: "ESNCC", AccountNumber: "0200051332"},
+		{Country: "CH", BankID: "00762", BankIDCode: "CHBCC"},
+		{Country: "US", BankID: "021000021", BankIDCode: "USABA", Bic: "CHASUS33"},
+		{Country: "JP"},
+	}
+
+	for _, attr := range tests {
+		if err := newValidAccount(attr).Validate(); err != nil {
+			t.Errorf("unexpected validation error on country %s, error %v", attr.Country, err)
+		}
+	}
+}
+
+func TestValidateReportsEveryViolation(t *testing.T) {
+	acc := newValidAccount(&Attributes{
+		Country:       "GB",
+		BaseCurrency:  "pounds",
+		BankID:        "4003",
+		BankIDCode:    "DEBLZ",
+		Bic:           "NW",
+		AccountNumber: "123",
+	})
+	acc.AccoundData.ID = "fakeID"
+
+	err := acc.Validate()
+	var v *ValidationError
+	if !errors.As(err, &v) {
+		t.Fatalf("unexpected error type, expected validation error got %v", err)
+	}
+
+	if !errors.Is(err, ErrInvalidAccount) {
+		t.Error("validation error does not match invalid account")
+	}
+
+	want := []string{
+		"data.attributes.account_number",
+		"data.attributes.bank_id",
+		"data.attributes.bank_id_code",
+		"data.attributes.base_currency",
+		"data.attributes.bic",
+		"data.id",
+	}
+	if got := fieldNames(v); !reflect.DeepEqual(got, want) {
+		t.Errorf("violated fields do not match, expected %v got %v", want, got)
+	}
+}
+
+func TestValidateRequiresAndForbidsCountryFields(t *testing.T) {
+	tests := []struct {
+		attr   *Attributes
+		fields []string
+	}{
+		{
+			attr:   &Attributes{Country: "GB"},
+			fields: []string{"data.attributes.bank_id", "data.attributes.bank_id_code", "data.attributes.bic"},
+		},
+		{
+			attr:   &Attributes{Country: "NL", Bic: "ABNANL2A", BankID: "1234", BankIDCode: "NLX"},
+			fields: []string{"data.attributes.bank_id", "data.attributes.bank_id_code"},
+		},
+		{
+			attr:   &Attributes{Country: "US", Bic: "CHASUS33", BankID: "021000021", BankIDCode: "USABA", Iban: "US00"},
+			fields: []string{"data.attributes.iban"},
+		},
+		{
+			attr:   &Attributes{Country: "AU", Bic: "NWBKAU22", BankIDCode: "AUBSB", AccountNumber: "0123456"},
+			fields: []string{"data.attributes.account_number"},
+		},
+		{
+			attr:   &Attributes{Country: "AU", Bic: "NWBKAU22", BankID: "033000"},
+			fields: []string{"data.attributes.bank_id_code"},
+		},
+		{
+			attr:   &Attributes{Country: "gb"},
+			fields: []string{"data.attributes.country"},
+		},
+	}
+
+	for _, test := range tests {
+		err := newValidAccount(test.attr).Validate()
+		var v *ValidationError
+		if !errors.As(err, &v) {
+			t.Fatalf("unexpected error type on country %s, got %v", test.attr.Country, err)
+		}
+
+		if got := fieldNames(v); !reflect.DeepEqual(got, test.fields) {
+			t.Errorf("violated fields on country %s do not match, expected %v got %v", test.attr.Country, test.fields, got)
+		}
+	}
+}
+
+func TestValidateChecksIbanChecksumAndCountry(t *testing.T) {
+	tests := []struct {
+		attr  *Attributes
+		valid bool
+	}{
+		{attr: &Attributes{Country: "DE", BankID: "37040044", BankIDCode: "DEBLZ", Iban: "DE89 3704 0044 0532 0130 00"}, valid: true},
+		{attr: &Attributes{Country: "DE", BankID: "37040044", BankIDCode: "DEBLZ", Iban: "DE88370400440532013000"}, valid: false},
+		{attr: &Attributes{Country: "DE", BankID: "37040044", BankIDCode: "DEBLZ", Iban: "ES9121000418450200051332"}, valid: false},
+	}
+
+	for _, test := range tests {
+		err := newValidAccount(test.attr).Validate()
+		if test.valid && err != nil {
+			t.Errorf("unexpected validation error on %s, error %v", test.attr.Iban, err)
+		}
+
+		if !test.valid && err == nil {
+			t.Errorf("expected validation error on %s", test.attr.Iban)
+		}
+	}
+}
+
+func TestValidateRequiresAccountData(t *testing.T) {
+	if err := (&Account{}).Validate(); !errors.Is(err, ErrInvalidAccount) {
+		t.Errorf("unexpected error type, expected invalid account got %v", err)
+	}
+
+	acc := newValidAccount(nil)
+	if err := acc.Validate(); err == nil {
+		t.Error("expected missing attributes error")
+	}
+}
+
+func TestCreateAccountWithValidationDoesNotSendInvalidAccounts(t *testing.T) {
+	h := &fakeHTTPClient{}
+	api := NewAPIClient(h, WithValidation())
+
+	_, err := api.Create(context.Background(), newValidAccount(&Attributes{Country: "GB"}))
+	var v *ValidationError
+	if !errors.As(err, &v) {
+		t.Fatalf("unexpected error type, expected validation error got %v", err)
+	}
+
+	if h.uri != "" {
+		t.Error("invalid account request has been sent")
+	}
+}
+
+func newValidAccount(attr *Attributes) *Account {
+	return &Account{
+		AccoundData: &AccoundData{
+			Type:           "accounts",
+			ID:             uuid.New().String(),
+			OrganisationID: uuid.New().String(),
+			Attributes:     attr,
+		},
+	}
+}
+
+func fieldNames(v *ValidationError) []string {
+	names := make([]string, len(v.Fields))
+	for i, f := range v.Fields {
+		names[i] = f.Field
+	}
+	sort.Strings(names)
+
+	return names
+}